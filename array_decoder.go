@@ -0,0 +1,253 @@
+package pgt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+
+	bat "github.com/robert-zaremba/go-bat"
+)
+
+// ArrayDecoder iterates over elements of one dimensional array text
+// representation without allocating: unquoted and quoted elements without
+// escapes are returned as sub-slices of the source, elements with escapes are
+// unescaped into a buffer reused between elements. Zero value is an exhausted
+// decoder, use Reset to start decoding:
+//
+//	var d pgt.ArrayDecoder
+//	d.Reset(src, ',')
+//	for d.Next() {
+//		use(d.Elem(), d.IsNull())
+//	}
+//	if err := d.Err(); err != nil {
+//		...
+//	}
+type ArrayDecoder struct {
+	src        []byte
+	pos        int
+	delim      byte
+	afterDelim bool
+	buf        []byte
+	elem       []byte
+	null       bool
+	err        error
+}
+
+// Reset starts decoding src with elements separated by delim. The internal
+// buffer is kept, so a decoder can be reused without allocations.
+func (d *ArrayDecoder) Reset(src []byte, delim byte) {
+	*d = ArrayDecoder{delim: delim, buf: d.buf[:0]}
+	if len(src) < 2 || src[0] != openingArray || src[len(src)-1] != closingArray {
+		d.err = errors.New("Can't decode array: expecting value in {}")
+		return
+	}
+	d.src = src[1 : len(src)-1]
+}
+
+// Next advances the decoder to the next element. It returns false when there
+// are no more elements or an error occurred.
+func (d *ArrayDecoder) Next() bool {
+	if d.err != nil {
+		return false
+	}
+	s := d.src
+	i := skipArraySpaces(s, d.pos)
+	if i == len(s) {
+		if d.afterDelim {
+			d.err = errors.New("Can't decode array: missing element after delimiter")
+		}
+		d.pos = i
+		return false
+	}
+	var err error
+	if s[i] == '"' {
+		i, err = d.readQuoted(i + 1)
+	} else {
+		i, err = d.readUnquoted(i)
+	}
+	if err != nil {
+		d.err = err
+		return false
+	}
+	i = skipArraySpaces(s, i)
+	d.afterDelim = false
+	if i < len(s) {
+		if s[i] != d.delim {
+			d.err = fmt.Errorf("Can't decode array: expecting delimiter at position %d", i+1)
+			return false
+		}
+		i++
+		d.afterDelim = true
+	}
+	d.pos = i
+	return true
+}
+
+func (d *ArrayDecoder) readQuoted(i int) (int, error) {
+	s := d.src
+	start := i
+	escaped := false
+	d.buf = d.buf[:0]
+	for i < len(s) && s[i] != '"' {
+		if s[i] == '\\' {
+			d.buf = append(d.buf, s[start:i]...)
+			escaped = true
+			i++
+			start = i
+		}
+		i++
+	}
+	if i >= len(s) {
+		return i, errors.New("Can't decode array: unterminated quoted element")
+	}
+	if escaped {
+		d.buf = append(d.buf, s[start:i]...)
+		d.elem = d.buf
+	} else {
+		d.elem = s[start:i]
+	}
+	d.null = false
+	return i + 1, nil
+}
+
+// Character classes used by the decoder
+const (
+	arrayCharPlain = iota
+	arrayCharSpace
+	arrayCharSpecial // not allowed in unquoted elements
+)
+
+var arrayCharClass = func() (cls [256]uint8) {
+	for _, c := range []byte(" \t\n\r\v\f") {
+		cls[c] = arrayCharSpace
+	}
+	for _, c := range []byte{'"', '\\', openingArray, closingArray} {
+		cls[c] = arrayCharSpecial
+	}
+	return
+}()
+
+func (d *ArrayDecoder) readUnquoted(i int) (int, error) {
+	s := d.src
+	start := i
+	end := i
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c == d.delim {
+			break
+		}
+		switch arrayCharClass[c] {
+		case arrayCharPlain:
+			end = i + 1
+		case arrayCharSpecial:
+			return i, fmt.Errorf("Can't decode array: unexpected %q at position %d", c, i+1)
+		}
+	}
+	if end == start {
+		return i, fmt.Errorf("Can't decode array: empty element at position %d", i+1)
+	}
+	d.elem = s[start:end]
+	d.null = len(d.elem) == 4 && (d.elem[0]|0x20) == 'n' && (d.elem[1]|0x20) == 'u' &&
+		(d.elem[2]|0x20) == 'l' && (d.elem[3]|0x20) == 'l'
+	return i, nil
+}
+
+func skipArraySpaces(s []byte, i int) int {
+	for i < len(s) && arrayCharClass[s[i]] == arrayCharSpace {
+		i++
+	}
+	return i
+}
+
+// Elem returns the current element. The result is valid only until the next
+// call to Next.
+func (d *ArrayDecoder) Elem() []byte { return d.elem }
+
+// IsNull returns true if the current element is NULL
+func (d *ArrayDecoder) IsNull() bool { return d.null }
+
+// Err returns the decoding error, if any
+func (d *ArrayDecoder) Err() error { return d.err }
+
+// DecodeArray calls fn for each element of one dimensional array text
+// representation, see ArrayDecoder. elem is valid only during the fn call.
+// Decoding stops on the first fn error, which is returned.
+func DecodeArray(src []byte, delim byte, fn func(elem []byte, null bool) error) error {
+	var d ArrayDecoder
+	d.Reset(src, delim)
+	for d.Next() {
+		if err := fn(d.elem, d.null); err != nil {
+			return err
+		}
+	}
+	return d.err
+}
+
+// arrayCapacity returns the maximum number of elements of a simple array src
+func arrayCapacity(src []byte) int {
+	return bytes.Count(src, []byte{arraySeparator}) + 1
+}
+
+// DecodeFloatArray appends elements of `float8[]` or `float4[]` text
+// representation to dst and returns the extended slice. Pass `dst[:0]` to reuse
+// the dst memory. NULL elements are not allowed.
+func DecodeFloatArray(dst []float64, src []byte) ([]float64, error) {
+	var d ArrayDecoder
+	d.Reset(src, arraySeparator)
+	for i := 0; d.Next(); i++ {
+		if d.null {
+			return dst, fmt.Errorf("Can't decode float array: NULL element at index %d", i)
+		}
+		v, err := parseFloatBytes(d.elem)
+		if err != nil {
+			return dst, fmt.Errorf("Can't decode float array element %d: %v", i, err)
+		}
+		dst = append(dst, v)
+	}
+	return dst, d.err
+}
+
+// DecodeInt64Array appends elements of integer array text representation to dst
+// and returns the extended slice. Pass `dst[:0]` to reuse the dst memory. NULL
+// elements are not allowed.
+func DecodeInt64Array(dst []int64, src []byte) ([]int64, error) {
+	var d ArrayDecoder
+	d.Reset(src, arraySeparator)
+	for i := 0; d.Next(); i++ {
+		if d.null {
+			return dst, fmt.Errorf("Can't decode integer array: NULL element at index %d", i)
+		}
+		v, err := parseIntBytes(d.elem)
+		if err != nil {
+			return dst, fmt.Errorf("Can't decode integer array element %d: %v", i, err)
+		}
+		dst = append(dst, v)
+	}
+	return dst, d.err
+}
+
+// parseIntBytes parses 10-based int64 from b without copying it
+func parseIntBytes(b []byte) (int64, error) {
+	i := 0
+	neg := false
+	if len(b) > 0 && (b[0] == '-' || b[0] == '+') {
+		neg = b[0] == '-'
+		i++
+	}
+	if i == len(b) || len(b)-i > 18 { // longer numbers may overflow
+		return strconv.ParseInt(bat.UnsafeByteArrayToStr(b), 10, 64)
+	}
+	var x int64
+	for ; i < len(b); i++ {
+		c := b[i]
+		if c < '0' || c > '9' {
+			return strconv.ParseInt(bat.UnsafeByteArrayToStr(b), 10, 64)
+		}
+		x = x*10 + int64(c-'0')
+	}
+	if neg {
+		x = -x
+	}
+	return x, nil
+}
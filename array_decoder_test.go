@@ -0,0 +1,277 @@
+package pgt
+
+import (
+	"database/sql"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/robert-zaremba/checkers"
+	bat "github.com/robert-zaremba/go-bat"
+	. "gopkg.in/check.v1"
+)
+
+type ArrayDecoderSuite struct{}
+
+type decodedElem struct {
+	Elem string
+	Null bool
+}
+
+func decodeAll(src string, delim byte) ([]decodedElem, error) {
+	res := []decodedElem{}
+	err := DecodeArray([]byte(src), delim, func(elem []byte, null bool) error {
+		res = append(res, decodedElem{string(elem), null})
+		return nil
+	})
+	return res, err
+}
+
+func (suite *ArrayDecoderSuite) TestDecodeArray(c *C) {
+	res, err := decodeAll(`{a, "b c" ,"\"x\\y\"",NULL,"NULL",null,""}`, ',')
+	c.Assert(err, IsNil)
+	c.Check(res, DeepEquals, []decodedElem{
+		{"a", false}, {"b c", false}, {`"x\y"`, false}, {"NULL", true},
+		{"NULL", false}, {"null", true}, {"", false}})
+
+	res, err = decodeAll(`{(1,1),(0,0);(3,3),(2,2)}`, ';')
+	c.Assert(err, IsNil)
+	c.Check(res, DeepEquals, []decodedElem{{"(1,1),(0,0)", false}, {"(3,3),(2,2)", false}})
+
+	for _, src := range []string{`{}`, `{ }`} {
+		res, err = decodeAll(src, ',')
+		c.Check(err, IsNil)
+		c.Check(res, HasLen, 0)
+	}
+
+	for _, src := range []string{``, `a`, `{a,}`, `{,a}`, `{"a}`, `{"a"b}`, `{a"b}`, `{{1},{2}}`, `{"a\"}`} {
+		_, err = decodeAll(src, ',')
+		c.Check(err, NotNil, Commentf("%q", src))
+	}
+}
+
+func (suite *ArrayDecoderSuite) TestDecodeNumbers(c *C) {
+	fs, err := DecodeFloatArray(nil, []byte(`{1,-0.5,1e-05,NaN,Infinity,-Infinity}`))
+	c.Assert(err, IsNil)
+	c.Check(fs[:3], DeepEquals, []float64{1, -0.5, 1e-5})
+	c.Check(math.IsNaN(fs[3]), IsTrue)
+	c.Check(fs[4:], DeepEquals, []float64{math.Inf(1), math.Inf(-1)})
+
+	is, err := DecodeInt64Array([]int64{7}, []byte(`{1, -2 ,3}`))
+	c.Assert(err, IsNil)
+	c.Check(is, DeepEquals, []int64{7, 1, -2, 3})
+
+	_, err = DecodeFloatArray(nil, []byte(`{1,NULL}`))
+	c.Check(err, ErrorMatches, ".*NULL element at index 1")
+	_, err = DecodeInt64Array(nil, []byte(`{1,2.5}`))
+	c.Check(err, ErrorMatches, "Can't decode integer array element 1: .*")
+}
+
+func (suite *ArrayDecoderSuite) TestScanAllocates(c *C) {
+	var f Float64s
+	c.Assert(f.Scan([]byte(`{1,2,3}`)), IsNil)
+	prev := f
+	c.Assert(f.Scan([]byte(`{4,5}`)), IsNil)
+	c.Check(f, DeepEquals, Float64s{4, 5})
+	c.Check(prev, DeepEquals, Float64s{1, 2, 3})
+
+	var src interface{} = []byte(`{4,5,6,7}`)
+	allocs := testing.AllocsPerRun(10, func() {
+		if err := f.Scan(src); err != nil {
+			panic(err)
+		}
+	})
+	c.Check(allocs, Equals, 1.0)
+
+	var ls Ints
+	c.Assert(ls.Scan([]byte(`{1,2}`)), IsNil)
+	prevInts := ls
+	c.Assert(ls.Scan([]byte(`{3}`)), IsNil)
+	c.Check(prevInts, DeepEquals, Ints{1, 2})
+	c.Assert(ls.Scan([]byte(`{}`)), IsNil)
+	c.Check(ls, NotNil)
+	c.Check(ls, HasLen, 0)
+	c.Check(ls.Scan([]byte(`{1,x}`)), NotNil)
+	c.Check(f.Scan([]byte(`{1,2`)), NotNil)
+	c.Check(f.Scan(nil), IsNil)
+	c.Check(f, IsNil)
+}
+
+func (suite *ArrayDecoderSuite) TestScanBuffer(c *C) {
+	var f Float64sBuffer
+	c.Assert(f.Scan([]byte(`{1,2,3}`)), IsNil)
+	prev := f
+	c.Assert(f.Scan([]byte(`{4,5}`)), IsNil)
+	c.Check(f, DeepEquals, Float64sBuffer{4, 5})
+	c.Check(prev[:2], DeepEquals, Float64sBuffer{4, 5}) // memory is reused
+	c.Check(mustValue(c, f), Equals, "{4,5}")
+
+	var src interface{} = []byte(`{4,5,6}`)
+	allocs := testing.AllocsPerRun(10, func() {
+		if err := f.Scan(src); err != nil {
+			panic(err)
+		}
+	})
+	c.Check(allocs, Equals, 0.0)
+	c.Assert(f.Scan([]byte(`{1,2,3,4,5}`)), IsNil) // grows
+	c.Check(f, DeepEquals, Float64sBuffer{1, 2, 3, 4, 5})
+
+	var ls IntsBuffer
+	c.Assert(ls.Scan([]byte(`{1,2}`)), IsNil)
+	prevInts := ls
+	c.Assert(ls.Scan([]byte(`{3}`)), IsNil)
+	c.Check(prevInts, DeepEquals, IntsBuffer{3, 2})
+	c.Check(mustValue(c, ls), Equals, "{3}")
+	c.Check(ls.Scan([]byte(`{1,x}`)), NotNil)
+	c.Check(ls.Scan(nil), IsNil)
+	c.Check(ls, IsNil)
+}
+
+func (suite *ArrayDecoderSuite) TestParseArrays(c *C) {
+	fs, err := ParseFloatArray([]byte(`{1.5,-2}`))
+	c.Assert(err, IsNil)
+	c.Check(fs, DeepEquals, []float64{1.5, -2})
+	fs, err = ParseFloatArray(EmptyArray)
+	c.Assert(err, IsNil)
+	c.Check(fs, NotNil)
+	c.Check(fs, HasLen, 0)
+	_, err = ParseFloatArray([]byte(`{1,x}`))
+	c.Check(err, NotNil)
+
+	is, err := ParseInt64Array([]byte(`{1,-2}`))
+	c.Assert(err, IsNil)
+	c.Check(is, DeepEquals, []int64{1, -2})
+	_, err = ParseInt64Array([]byte(`{1,NULL}`))
+	c.Check(err, NotNil)
+}
+
+// embeddingArray returns float array with n elements. bitSize defines precision
+// of the elements text representation: 32 for `float4[]` and 64 for `float8[]`.
+func embeddingArray(n, bitSize int) []byte {
+	rnd := rand.New(rand.NewSource(1))
+	b := []byte{openingArray}
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b = append(b, arraySeparator)
+		}
+		b = strconv.AppendFloat(b, rnd.NormFloat64()*0.05, 'g', -1, bitSize)
+	}
+	return append(b, closingArray)
+}
+
+// splitParseFloatArray is the previous ParseFloatArray implementation, used as
+// the benchmarks baseline
+func splitParseFloatArray(src []byte) ([]float64, error) {
+	vals := SplitSimpleArray(src)
+	var results = make([]float64, len(vals))
+	var err error
+	for i := range vals {
+		if results[i], err = bat.Atof64(bat.UnsafeByteArrayToStr(vals[i])); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+func benchmarkSplitFloatArray(b *testing.B, bitSize int) {
+	src := embeddingArray(1536, bitSize)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		if _, err := splitParseFloatArray(src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkDecodeFloatArray(b *testing.B, bitSize int) {
+	src := embeddingArray(1536, bitSize)
+	var dst []float64
+	var err error
+	b.ReportAllocs()
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		if dst, err = DecodeFloatArray(dst[:0], src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSplitFloatArray4(b *testing.B)  { benchmarkSplitFloatArray(b, 32) }
+func BenchmarkDecodeFloatArray4(b *testing.B) { benchmarkDecodeFloatArray(b, 32) }
+func BenchmarkSplitFloatArray8(b *testing.B)  { benchmarkSplitFloatArray(b, 64) }
+func BenchmarkDecodeFloatArray8(b *testing.B) { benchmarkDecodeFloatArray(b, 64) }
+
+func benchmarkScan(b *testing.B, dst sql.Scanner) {
+	var src interface{} = embeddingArray(1536, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := dst.Scan(src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFloat64sScan(b *testing.B)       { benchmarkScan(b, new(Float64s)) }
+func BenchmarkFloat64sBufferScan(b *testing.B) { benchmarkScan(b, new(Float64sBuffer)) }
+
+func BenchmarkDecodeStringArray(b *testing.B) {
+	src := []byte(`{` + strings.Repeat(`abc,"d e","f\"g",`, 100) + `x}`)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		n := 0
+		if err := DecodeArray(src, ',', func([]byte, bool) error { n++; return nil }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func (suite *ArrayDecoderSuite) TestParseFloatBytes(c *C) {
+	for _, s := range []string{"0", "-0", "1", "+1.5", "0.05", "123456789012345", "1234567890123456789",
+		"0.1", "0.30000000000000004", "1e22", "1e23", "1.5e-7", "-2.5E+3", "4.9e-324",
+		".5", "5.", "NaN", "Infinity", "-inf"} {
+		expected, err := strconv.ParseFloat(s, 64)
+		c.Assert(err, IsNil, Commentf("%s", s))
+		f, err := parseFloatBytes([]byte(s))
+		c.Check(err, IsNil, Commentf("%s", s))
+		c.Check(math.Float64bits(f), Equals, math.Float64bits(expected), Commentf("%s", s))
+	}
+	for _, s := range []string{"", "-", ".", "e5", "1e", "1.5.2", "1x", "--1", "1e400"} {
+		_, err := parseFloatBytes([]byte(s))
+		c.Check(err, NotNil, Commentf("%s", s))
+	}
+
+	// shortest representations and long mantissas in a wide range of exponents
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		x := math.Float64frombits(rnd.Uint64())
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			continue
+		}
+		ss := []string{
+			strconv.FormatFloat(x, 'g', -1, 64),
+			strconv.FormatFloat(float64(float32(x)), 'g', -1, 32),
+			strconv.FormatFloat(rnd.NormFloat64()*0.05, 'g', -1, 64),
+			strconv.FormatUint(rnd.Uint64()%1e19, 10) + "e" + strconv.Itoa(rnd.Intn(300)-150),
+		}
+		for _, s := range ss {
+			expected, _ := strconv.ParseFloat(s, 64)
+			f, err := parseFloatBytes([]byte(s))
+			c.Assert(err, IsNil, Commentf("%s", s))
+			c.Assert(math.Float64bits(f), Equals, math.Float64bits(expected), Commentf("%s", s))
+		}
+	}
+
+	for _, s := range []string{"0", "-12", "+7", "123456789012345678", "9223372036854775807", "-9223372036854775808"} {
+		expected, _ := strconv.ParseInt(s, 10, 64)
+		x, err := parseIntBytes([]byte(s))
+		c.Check(err, IsNil)
+		c.Check(x, Equals, expected)
+	}
+	for _, s := range []string{"", "-", "1.0", "9223372036854775808", "1_0"} {
+		_, err := parseIntBytes([]byte(s))
+		c.Check(err, NotNil, Commentf("%s", s))
+	}
+}
@@ -0,0 +1,278 @@
+package pgt
+
+import (
+	"iter"
+	"math"
+)
+
+// The functions below implement Postgresql array operators and functions for
+// all pgt array types (and plain slices of their element types), so the same
+// predicates can be evaluated in memory and in SQL. Elements are compared as
+// Postgresql does:
+//   - NULL elements (invalid String, Time, InfTime, Date and NullFixedUUID,
+//     values with IsNull method returning true) never match in `&&`, `@>` and
+//     `<@`, but are matched by NULL in `array_position`, `array_remove`,
+//     `array_replace` and `=` (which use `IS NOT DISTINCT FROM` semantics);
+//   - NaN equals NaN and 0 equals -0, for both float64 and float32;
+//   - CIText elements are compared case insensitively;
+//   - times are compared as instants, independently of their locations.
+// Other element types are compared with the Go `==` operator.
+// Non comparable elements, eg: UUID, are compared by a comparable key with the
+// *Func variants. `ArrayOverlapFunc(a, b, UUID.NullFixed)` compares UUIDs and
+// treats empty UUIDs as NULLs.
+
+type nanKey struct{}
+
+// arrayElemKey returns a comparable key of an array element, equal for elements
+// equal in Postgresql, and reports if the element is NULL.
+func arrayElemKey(v interface{}) (key interface{}, null bool) {
+	switch x := v.(type) {
+	case float64:
+		switch {
+		case math.IsNaN(x):
+			return nanKey{}, false // NaN can't be a map key
+		case x == 0:
+			return 0.0, false
+		}
+		return x, false
+	case float32:
+		switch {
+		case x != x:
+			return nanKey{}, false
+		case x == 0:
+			return float32(0), false
+		}
+		return x, false
+	case String:
+		return x.String, !x.Valid
+	case CIText:
+		return x.Fold(), !x.Valid
+	case Time:
+		if !x.Valid {
+			return nil, true
+		}
+		return timeKeyOf(InfTime{Time: x}), false
+	case InfTime:
+		if !x.Valid {
+			return nil, true
+		}
+		return timeKeyOf(x), false
+	case Date:
+		if !x.Valid {
+			return nil, true
+		}
+		return dateKeyOf(x), false
+	case NullFixedUUID:
+		return x.UUID, !x.Valid
+	case interface{ IsNull() bool }: // eg: Enum
+		return v, x.IsNull()
+	}
+	return v, v == nil
+}
+
+// arrayKeySet returns the set of keys of non NULL elements of a
+func arrayKeySet[S ~[]E, E any, K comparable](a S, key func(E) K) map[interface{}]struct{} {
+	set := make(map[interface{}]struct{}, len(a))
+	for _, e := range a {
+		if k, null := arrayElemKey(key(e)); !null {
+			set[k] = struct{}{}
+		}
+	}
+	return set
+}
+
+// notDistinct returns true if a and b are equal or both NULL
+func notDistinct[K comparable](a, b K) bool {
+	ka, nullA := arrayElemKey(a)
+	kb, nullB := arrayElemKey(b)
+	if nullA || nullB {
+		return nullA == nullB
+	}
+	return ka == kb
+}
+
+func identity[E any](e E) E { return e }
+
+// ArrayOverlap implements the `a && b` operator: it returns true if the arrays
+// have a common, non NULL element.
+func ArrayOverlap[S ~[]E, E comparable](a, b S) bool {
+	return ArrayOverlapFunc(a, b, identity[E])
+}
+
+// ArrayOverlapFunc is like ArrayOverlap, but compares elements by key. It is
+// used for non comparable element types, eg: `ArrayOverlapFunc(a, b,
+// UUID.NullFixed)`.
+func ArrayOverlapFunc[S ~[]E, E any, K comparable](a, b S, key func(E) K) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	set := arrayKeySet(a, key)
+	for _, e := range b {
+		if k, null := arrayElemKey(key(e)); !null {
+			if _, ok := set[k]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ArrayContains implements the `a @> b` operator: it returns true if every
+// element of b is in a. Duplicates are ignored, and a NULL element of b is
+// never contained.
+func ArrayContains[S ~[]E, E comparable](a, b S) bool {
+	return ArrayContainsFunc(a, b, identity[E])
+}
+
+// ArrayContainsFunc is like ArrayContains, but compares elements by key
+func ArrayContainsFunc[S ~[]E, E any, K comparable](a, b S, key func(E) K) bool {
+	if len(b) == 0 {
+		return true
+	}
+	set := arrayKeySet(a, key)
+	for _, e := range b {
+		k, null := arrayElemKey(key(e))
+		if null {
+			return false
+		}
+		if _, ok := set[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ArrayContainedBy implements the `a <@ b` operator. See ArrayContains.
+func ArrayContainedBy[S ~[]E, E comparable](a, b S) bool {
+	return ArrayContains(b, a)
+}
+
+// ArrayContainedByFunc is like ArrayContainedBy, but compares elements by key
+func ArrayContainedByFunc[S ~[]E, E any, K comparable](a, b S, key func(E) K) bool {
+	return ArrayContainsFunc(b, a, key)
+}
+
+// ArrayEqual implements the `a = b` operator for one dimensional arrays: the
+// arrays must have the same length and equal elements in the same order. NULL
+// elements are equal to each other.
+func ArrayEqual[S ~[]E, E comparable](a, b S) bool {
+	return ArrayEqualFunc(a, b, identity[E])
+}
+
+// ArrayEqualFunc is like ArrayEqual, but compares elements by key
+func ArrayEqualFunc[S ~[]E, E any, K comparable](a, b S, key func(E) K) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !notDistinct(key(a[i]), key(b[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// ArrayPosition implements `array_position(a, v)`: it returns the 1-based
+// position of the first occurrence of v in a, or 0 (SQL NULL) if v is not found.
+func ArrayPosition[S ~[]E, E comparable](a S, v E) int {
+	return ArrayPositionFunc(a, v, identity[E])
+}
+
+// ArrayPositionFunc is like ArrayPosition, but compares elements by key
+func ArrayPositionFunc[S ~[]E, E any, K comparable](a S, v E, key func(E) K) int {
+	kv := key(v)
+	for i := range a {
+		if notDistinct(key(a[i]), kv) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// ArrayPositions implements `array_positions(a, v)`: it returns the 1-based
+// positions of all occurrences of v in a.
+func ArrayPositions[S ~[]E, E comparable](a S, v E) []int {
+	return ArrayPositionsFunc(a, v, identity[E])
+}
+
+// ArrayPositionsFunc is like ArrayPositions, but compares elements by key
+func ArrayPositionsFunc[S ~[]E, E any, K comparable](a S, v E, key func(E) K) []int {
+	if a == nil {
+		return nil
+	}
+	kv := key(v)
+	res := []int{}
+	for i := range a {
+		if notDistinct(key(a[i]), kv) {
+			res = append(res, i+1)
+		}
+	}
+	return res
+}
+
+// ArrayRemove implements `array_remove(a, v)`: it returns a copy of a without
+// elements equal to v. NULL v removes NULL elements.
+func ArrayRemove[S ~[]E, E comparable](a S, v E) S {
+	return ArrayRemoveFunc(a, v, identity[E])
+}
+
+// ArrayRemoveFunc is like ArrayRemove, but compares elements by key
+func ArrayRemoveFunc[S ~[]E, E any, K comparable](a S, v E, key func(E) K) S {
+	if a == nil {
+		return nil
+	}
+	kv := key(v)
+	res := make(S, 0, len(a))
+	for _, e := range a {
+		if !notDistinct(key(e), kv) {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// ArrayReplace implements `array_replace(a, from, to)`: it returns a copy of a
+// with elements equal to from replaced by to. NULL from replaces NULL elements.
+func ArrayReplace[S ~[]E, E comparable](a S, from, to E) S {
+	return ArrayReplaceFunc(a, from, to, identity[E])
+}
+
+// ArrayReplaceFunc is like ArrayReplace, but compares elements by key
+func ArrayReplaceFunc[S ~[]E, E any, K comparable](a S, from, to E, key func(E) K) S {
+	if a == nil {
+		return nil
+	}
+	kf := key(from)
+	res := make(S, len(a))
+	for i, e := range a {
+		if notDistinct(key(e), kf) {
+			res[i] = to
+		} else {
+			res[i] = e
+		}
+	}
+	return res
+}
+
+// ArrayCat implements `array_cat(a, b)` (the `||` operator): it returns a new
+// array with elements of a followed by elements of b. The result is nil (SQL
+// NULL) only if both arrays are nil.
+func ArrayCat[S ~[]E, E any](a, b S) S {
+	if a == nil && b == nil {
+		return nil
+	}
+	res := make(S, 0, len(a)+len(b))
+	return append(append(res, a...), b...)
+}
+
+// UnnestWithOrdinality implements `unnest(a) WITH ORDINALITY`: it yields array
+// elements with their 1-based positions.
+func UnnestWithOrdinality[S ~[]E, E any](a S) iter.Seq2[int, E] {
+	return func(yield func(int, E) bool) {
+		for i, e := range a {
+			if !yield(i+1, e) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package pgt
+
+import (
+	"math"
+	"time"
+
+	. "github.com/robert-zaremba/checkers"
+	. "gopkg.in/check.v1"
+)
+
+type ArrayOpsSuite struct{}
+
+func (suite *ArrayOpsSuite) TestOverlapAndContains(c *C) {
+	a := Ints{1, 2, 3, 2}
+	c.Check(ArrayOverlap(a, Ints{5, 3}), IsTrue)
+	c.Check(ArrayOverlap(a, Ints{5}), IsFalse)
+	c.Check(ArrayOverlap(a, nil), IsFalse)
+	c.Check(ArrayContains(a, Ints{2, 2, 1}), IsTrue)
+	c.Check(ArrayContains(a, Ints{}), IsTrue)
+	c.Check(ArrayContains(a, Ints{4}), IsFalse)
+	c.Check(ArrayContainedBy(Ints{3, 3}, a), IsTrue)
+	c.Check(ArrayContainedBy(a, Ints{1, 2}), IsFalse)
+
+	// NULLs never match
+	null := []String{{}, NewString("a", false)}
+	c.Check(ArrayOverlap(null, []String{{}}), IsFalse)
+	c.Check(ArrayContains(null, []String{{}}), IsFalse)
+	c.Check(ArrayContains(null, []String{NewString("a", false)}), IsTrue)
+	c.Check(ArrayContainsFunc(NullUUIDs{nil}, NullUUIDs{nil}, UUID.NullFixed), IsFalse)
+
+	// NaN equals NaN, 0 equals -0
+	c.Check(ArrayContains(Float64s{math.NaN(), 0}, Float64s{math.NaN(), math.Copysign(0, -1)}), IsTrue)
+	nan32 := float32(math.NaN())
+	c.Check(ArrayContains([]float32{nan32, 0}, []float32{nan32, float32(math.Copysign(0, -1))}), IsTrue)
+	c.Check(ArrayEqual([]float32{nan32}, []float32{nan32}), IsTrue)
+
+	// times are compared as instants
+	t := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	local := Time{Time: t.In(time.FixedZone("CET", 3600)), Valid: true}
+	c.Check(ArrayOverlap([]Time{NewTime(t)}, []Time{local}), IsTrue)
+	c.Check(ArrayOverlap(Times{NewInfTime(t)}, Times{{Time: local}}), IsTrue)
+	c.Check(ArrayOverlap(Times{TimeInfinity, {}}, Times{{}, TimeInfinity}), IsTrue)
+
+	u := RandomUUID()
+	c.Check(ArrayOverlapFunc(UUIDs{u}, UUIDs{u.Fixed().UUID()}, UUID.NullFixed), IsTrue)
+	c.Check(ArrayContainedByFunc(UUIDs{u}, UUIDs{RandomUUID()}, UUID.NullFixed), IsFalse)
+	c.Check(ArrayOverlap(FixedUUIDs{{}}, FixedUUIDs{{}}), IsTrue)
+	c.Check(ArrayOverlap(Strings{"a", "b"}, Strings{"B"}), IsFalse)
+}
+
+func (suite *ArrayOpsSuite) TestEqual(c *C) {
+	c.Check(ArrayEqual(Ints{1, 2}, Ints{1, 2}), IsTrue)
+	c.Check(ArrayEqual(Ints{1, 2}, Ints{2, 1}), IsFalse)
+	c.Check(ArrayEqual(Ints{1}, Ints{1, 1}), IsFalse)
+	c.Check(ArrayEqual(Dates{{}, DateInfinity}, Dates{{}, DateInfinity}), IsTrue)
+	c.Check(ArrayEqual(Dates{{}}, Dates{NewDate(2020, 1, 1)}), IsFalse)
+	c.Check(ArrayEqual(Float64s{math.NaN()}, Float64s{math.NaN()}), IsTrue)
+}
+
+func (suite *ArrayOpsSuite) TestPositions(c *C) {
+	a := NullUUIDs{nil, RandomUUID(), nil}
+	c.Check(ArrayPositionFunc(a, nil, UUID.NullFixed), Equals, 1)
+	c.Check(ArrayPositionFunc(a, a[1], UUID.NullFixed), Equals, 2)
+	c.Check(ArrayPositionFunc(a, RandomUUID(), UUID.NullFixed), Equals, 0)
+	c.Check(ArrayPositionsFunc(a, nil, UUID.NullFixed), DeepEquals, []int{1, 3})
+	c.Check(ArrayPositionsFunc(a, RandomUUID(), UUID.NullFixed), DeepEquals, []int{})
+	c.Check(ArrayPositionsFunc(NullUUIDs(nil), nil, UUID.NullFixed), IsNil)
+	c.Check(ArrayPosition(Strings{"a", "b", "a"}, "a"), Equals, 1)
+}
+
+func (suite *ArrayOpsSuite) TestRemoveReplaceCat(c *C) {
+	a := Strings{"a", "b", "a"}
+	c.Check(ArrayRemove(a, "a"), DeepEquals, Strings{"b"})
+	c.Check(ArrayRemove(a, "x"), DeepEquals, a)
+	c.Check(ArrayRemove(Strings(nil), "a"), IsNil)
+	c.Check(ArrayReplace(a, "a", "c"), DeepEquals, Strings{"c", "b", "c"})
+	c.Check(a, DeepEquals, Strings{"a", "b", "a"})
+
+	d := NewDate(2020, 1, 1)
+	c.Check(ArrayRemove(Dates{{}, d, {}}, Date{}), DeepEquals, Dates{d})
+	c.Check(ArrayReplace(Dates{{}, d}, Date{}, DateInfinity), DeepEquals, Dates{DateInfinity, d})
+
+	u := RandomUUID()
+	ids := NullUUIDs{nil, u, nil}
+	c.Check(ArrayRemoveFunc(ids, nil, UUID.NullFixed), DeepEquals, NullUUIDs{u})
+	c.Check(ArrayReplaceFunc(ids, nil, u, UUID.NullFixed), DeepEquals, NullUUIDs{u, u, u})
+	c.Check(ArrayEqualFunc(ids, NullUUIDs{nil, u.Fixed().UUID(), nil}, UUID.NullFixed), IsTrue)
+
+	c.Check(ArrayCat(Ints{1}, Ints{2, 3}), DeepEquals, Ints{1, 2, 3})
+	c.Check(ArrayCat(nil, Ints{}), DeepEquals, Ints{})
+	c.Check(ArrayCat(Ints(nil), nil), IsNil)
+}
+
+func (suite *ArrayOpsSuite) TestUnnestWithOrdinality(c *C) {
+	var res []string
+	for i, s := range UnnestWithOrdinality(Strings{"a", "b", "c"}) {
+		if i == 3 {
+			break
+		}
+		res = append(res, string(rune('0'+i))+s)
+	}
+	c.Check(res, DeepEquals, []string{"1a", "2b"})
+}
+
+func (suite *ArrayOpsSuite) TestStringsSets(c *C) {
+	s := Strings{"a", "b", "c"}
+	c.Check(s.ContainsAll([]string{"c", "a", "a"}), IsTrue)
+	c.Check(s.ContainsAll(nil), IsTrue)
+	c.Check(s.ContainsAll([]string{"d"}), IsFalse)
+	c.Check(s.Exclude("b", "d"), DeepEquals, Strings{"a", "c"})
+	c.Check(Strings(nil).Exclude(), DeepEquals, Strings{})
+}
@@ -0,0 +1,275 @@
+package pgt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Binary encodings (MarshalBinary) of the nullable wrappers start with a tag byte.
+// The high nibble of the tag is the encoding version, the low nibble is the value
+// state. The value payload (if any) follows the tag.
+const binaryVersion = 1
+
+// binary value states
+const (
+	binaryNull byte = iota
+	binaryValid
+	binaryPosInfinity
+	binaryNegInfinity
+)
+
+func binaryTag(state byte) byte {
+	return binaryVersion<<4 | state
+}
+
+// readBinaryTag validates the tag of the binary encoded value of the typ type and
+// returns the value state and the payload.
+func readBinaryTag(data []byte, typ string) (byte, []byte, error) {
+	if len(data) == 0 {
+		return 0, nil, fmt.Errorf("Can't unmarshal %s: empty data", typ)
+	}
+	if v := data[0] >> 4; v != binaryVersion {
+		return 0, nil, fmt.Errorf("Can't unmarshal %s: unsupported encoding version %d", typ, v)
+	}
+	state := data[0] & 0x0f
+	if state > binaryNegInfinity {
+		return 0, nil, fmt.Errorf("Can't unmarshal %s: wrong state %d", typ, state)
+	}
+	if state != binaryValid && len(data) != 1 {
+		return 0, nil, fmt.Errorf("Can't unmarshal %s: unexpected payload", typ)
+	}
+	return state, data[1:], nil
+}
+
+// readBinaryVarint reads exactly one varint from the payload
+func readBinaryVarint(payload []byte, typ string) (int64, error) {
+	x, n := binary.Varint(payload)
+	if n <= 0 || n != len(payload) {
+		return 0, fmt.Errorf("Can't unmarshal %s: malformed payload", typ)
+	}
+	return x, nil
+}
+
+func marshalBinaryVarint(x int64) []byte {
+	bs := make([]byte, 1+binary.MaxVarintLen64)
+	bs[0] = binaryTag(binaryValid)
+	n := binary.PutVarint(bs[1:], x)
+	return bs[:1+n]
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (s String) MarshalBinary() ([]byte, error) {
+	if !s.Valid {
+		return []byte{binaryTag(binaryNull)}, nil
+	}
+	bs := make([]byte, 1+len(s.String))
+	bs[0] = binaryTag(binaryValid)
+	copy(bs[1:], s.String)
+	return bs, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (s *String) UnmarshalBinary(data []byte) error {
+	state, payload, err := readBinaryTag(data, "String")
+	if err != nil {
+		return err
+	}
+	if state != binaryValid {
+		*s = String{}
+		return nil
+	}
+	*s = String{String: string(payload), Valid: true}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (s Int64) MarshalBinary() ([]byte, error) {
+	if !s.Valid {
+		return []byte{binaryTag(binaryNull)}, nil
+	}
+	return marshalBinaryVarint(s.Int64), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (s *Int64) UnmarshalBinary(data []byte) error {
+	state, payload, err := readBinaryTag(data, "Int64")
+	if err != nil {
+		return err
+	}
+	if state != binaryValid {
+		*s = Int64{}
+		return nil
+	}
+	x, err := readBinaryVarint(payload, "Int64")
+	if err != nil {
+		return err
+	}
+	*s = Int64{Int64: x, Valid: true}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (s Float64) MarshalBinary() ([]byte, error) {
+	if !s.Valid {
+		return []byte{binaryTag(binaryNull)}, nil
+	}
+	bs := make([]byte, 9)
+	bs[0] = binaryTag(binaryValid)
+	binary.BigEndian.PutUint64(bs[1:], math.Float64bits(s.Float64))
+	return bs, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (s *Float64) UnmarshalBinary(data []byte) error {
+	state, payload, err := readBinaryTag(data, "Float64")
+	if err != nil {
+		return err
+	}
+	if state != binaryValid {
+		*s = Float64{}
+		return nil
+	}
+	if len(payload) != 8 {
+		return fmt.Errorf("Can't unmarshal Float64: wrong payload length %d", len(payload))
+	}
+	*s = Float64{Float64: math.Float64frombits(binary.BigEndian.Uint64(payload)), Valid: true}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Finite dates are encoded as
+// the number of days since 1970-01-01.
+func (d Date) MarshalBinary() ([]byte, error) {
+	switch {
+	case !d.Valid:
+		return []byte{binaryTag(binaryNull)}, nil
+	case d.Inf == PosInfinity:
+		return []byte{binaryTag(binaryPosInfinity)}, nil
+	case d.Inf == NegInfinity:
+		return []byte{binaryTag(binaryNegInfinity)}, nil
+	}
+	return marshalBinaryVarint(int64(d.Sub(unixEpochDate))), nil
+}
+
+var unixEpochDate = NewDate(1970, time.January, 1)
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (d *Date) UnmarshalBinary(data []byte) error {
+	state, payload, err := readBinaryTag(data, "Date")
+	if err != nil {
+		return err
+	}
+	switch state {
+	case binaryNull:
+		*d = Date{}
+	case binaryPosInfinity:
+		*d = DateInfinity
+	case binaryNegInfinity:
+		*d = DateNegInfinity
+	default:
+		days, err := readBinaryVarint(payload, "Date")
+		if err != nil {
+			return err
+		}
+		*d = unixEpochDate.AddDays(int(days))
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (t TimeOfDay) MarshalBinary() ([]byte, error) {
+	if !t.Valid {
+		return []byte{binaryTag(binaryNull)}, nil
+	}
+	return marshalBinaryVarint(t.Microseconds), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (t *TimeOfDay) UnmarshalBinary(data []byte) error {
+	state, payload, err := readBinaryTag(data, "TimeOfDay")
+	if err != nil {
+		return err
+	}
+	if state != binaryValid {
+		*t = TimeOfDay{}
+		return nil
+	}
+	usec, err := readBinaryVarint(payload, "TimeOfDay")
+	if err != nil {
+		return err
+	}
+	*t = TimeOfDay{Microseconds: usec, Valid: true}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (t TimeOfDayTZ) MarshalBinary() ([]byte, error) {
+	if !t.Valid {
+		return []byte{binaryTag(binaryNull)}, nil
+	}
+	bs := marshalBinaryVarint(t.Microseconds)
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(buf[:], int64(t.Offset))
+	return append(bs, buf[:n]...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (t *TimeOfDayTZ) UnmarshalBinary(data []byte) error {
+	state, payload, err := readBinaryTag(data, "TimeOfDayTZ")
+	if err != nil {
+		return err
+	}
+	if state != binaryValid {
+		*t = TimeOfDayTZ{}
+		return nil
+	}
+	usec, n := binary.Varint(payload)
+	if n <= 0 {
+		return fmt.Errorf("Can't unmarshal TimeOfDayTZ: malformed payload")
+	}
+	offset, err := readBinaryVarint(payload[n:], "TimeOfDayTZ")
+	if err != nil {
+		return err
+	}
+	*t = TimeOfDayTZ{TimeOfDay{usec, true}, int(offset)}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding consists of the
+// tag, the length of the Time encoding, the Time encoding and the zone name.
+func (z ZonedTime) MarshalBinary() ([]byte, error) {
+	if !z.Valid {
+		return []byte{binaryTag(binaryNull)}, nil
+	}
+	t, err := z.Time.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	bs := make([]byte, 0, 2+len(t)+len(z.Zone))
+	bs = append(bs, binaryTag(binaryValid), byte(len(t)))
+	bs = append(bs, t...)
+	return append(bs, z.Zone...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (z *ZonedTime) UnmarshalBinary(data []byte) error {
+	state, payload, err := readBinaryTag(data, "ZonedTime")
+	if err != nil {
+		return err
+	}
+	if state != binaryValid {
+		*z = ZonedTime{}
+		return nil
+	}
+	if len(payload) == 0 || len(payload) < 1+int(payload[0]) {
+		return fmt.Errorf("Can't unmarshal ZonedTime: malformed payload")
+	}
+	n := 1 + int(payload[0])
+	var t Time
+	if err = t.UnmarshalBinary(payload[1:n]); err != nil {
+		return err
+	}
+	*z = ZonedTime{t, string(payload[n:])}
+	return nil
+}
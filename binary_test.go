@@ -0,0 +1,120 @@
+package pgt
+
+import (
+	"encoding"
+	"encoding/binary"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type BinarySuite struct{}
+
+func checkBinary(c *C, src encoding.BinaryMarshaler, dest encoding.BinaryUnmarshaler, size int) {
+	comment := Commentf("%T %v", src, src)
+	b, err := src.MarshalBinary()
+	c.Assert(err, IsNil, comment)
+	if size > 0 {
+		c.Check(b, HasLen, size, comment)
+	}
+	c.Assert(dest.UnmarshalBinary(b), IsNil, comment)
+}
+
+func (suite *BinarySuite) TestTimeBinary(c *C) {
+	testCases := []struct {
+		t    Time
+		size int
+	}{
+		{Time{}, 1},
+		{NewTime(time.Unix(1577934245, 0)), 5},
+		{NewTime(time.Unix(1577934245, 123456789)), 9},
+		{NewTime(time.Unix(1<<34, 1)), 13},
+		{NewTime(time.Date(-43, 3, 15, 12, 0, 0, 5, time.UTC)), 13},
+	}
+	for _, tc := range testCases {
+		var dest Time
+		checkBinary(c, tc.t, &dest, tc.size)
+		c.Check(dest, Equals, tc.t)
+		b, err := tc.t.MarshalBinary()
+		c.Assert(err, IsNil)
+		c.Check(b[0]>>4, Equals, byte(binaryVersion))
+	}
+
+	var t Time
+	for _, b := range [][]byte{nil, {0x01}, {0x21}, {1, 2, 3}, make([]byte, 17),
+		{0x11}, {0x11, 1, 2, 3}, {0x10, 1, 2, 3, 4}, {0x01, 1, 2, 3, 4},
+		{0x11, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0}} {
+		c.Check(t.UnmarshalBinary(b), NotNil, Commentf("%v", b))
+	}
+	b, err := TimeInfinity.MarshalBinary()
+	c.Assert(err, IsNil)
+	c.Check(t.UnmarshalBinary(b), ErrorMatches, ".*use InfTime")
+}
+
+func (suite *BinarySuite) TestInfTimeBinary(c *C) {
+	for _, v := range []InfTime{{}, TimeInfinity, TimeNegInfinity,
+		NewInfTime(time.Unix(1577934245, 123456789))} {
+		var dest InfTime
+		checkBinary(c, v, &dest, 0)
+		c.Check(dest, Equals, v)
+	}
+	for _, b := range [][]byte{{0x21}, {0x12, 0}} {
+		var dest InfTime
+		c.Check(dest.UnmarshalBinary(b), NotNil, Commentf("%v", b))
+	}
+}
+
+func (suite *BinarySuite) TestTimeBinaryLegacy(c *C) {
+	legacy := make([]byte, 16)
+	binary.PutVarint(legacy, 1577934245)
+	binary.PutVarint(legacy[8:], 1<<20)
+	var t Time
+	c.Assert(t.UnmarshalBinary(legacy), IsNil)
+	c.Check(t, Equals, NewTime(time.Unix(1577934245, 1<<20)))
+}
+
+func (suite *BinarySuite) TestWrappersBinary(c *C) {
+	for _, v := range []String{{}, NewString("", false), NewString("zażółć", false)} {
+		var dest String
+		checkBinary(c, v, &dest, 0)
+		c.Check(dest, Equals, v)
+	}
+	for _, v := range []Int64{{}, {Int64: -5, Valid: true}} {
+		var dest Int64
+		checkBinary(c, v, &dest, 0)
+		c.Check(dest, Equals, v)
+	}
+	for _, v := range []Float64{{}, {Float64: 1.5, Valid: true}} {
+		var dest Float64
+		checkBinary(c, v, &dest, 0)
+		c.Check(dest, Equals, v)
+	}
+	for _, v := range []Date{{}, DateInfinity, NewDate(2020, 2, 29), NewDate(-43, 3, 15)} {
+		var dest Date
+		checkBinary(c, v, &dest, 0)
+		c.Check(dest, Equals, v)
+	}
+	for _, v := range []TimeOfDayTZ{{}, {NewTimeOfDay(10, 0, 0, 5), -3600}} {
+		var dest TimeOfDayTZ
+		checkBinary(c, v, &dest, 0)
+		c.Check(dest, Equals, v)
+		var tod TimeOfDay
+		checkBinary(c, v.TimeOfDay, &tod, 0)
+		c.Check(tod, Equals, v.TimeOfDay)
+	}
+	z, err := NewZonedTime(time.Unix(1577934245, 0), "Europe/Warsaw")
+	c.Assert(err, IsNil)
+	for _, v := range []ZonedTime{{}, z} {
+		var dest ZonedTime
+		checkBinary(c, v, &dest, 0)
+		c.Check(dest, Equals, v)
+	}
+
+	var s String
+	c.Check(s.UnmarshalBinary([]byte{0x20}), NotNil)
+	c.Check(s.UnmarshalBinary([]byte{0x10, 'a'}), NotNil)
+	var i Int64
+	c.Check(i.UnmarshalBinary([]byte{0x11}), NotNil)
+	var d Date
+	c.Check(d.UnmarshalBinary([]byte{0x11, 2, 3}), NotNil)
+}
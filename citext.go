@@ -0,0 +1,140 @@
+package pgt
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+
+	bat "github.com/robert-zaremba/go-bat"
+)
+
+// CIText is a nullable string for Postgresql `citext` type. Scan, Value and JSON
+// encoding are the same as for String, while comparisons are case insensitive:
+// strings are compared after lowercasing, as citext does.
+type CIText sql.NullString
+
+// NewCIText creates CIText from the specified string
+func NewCIText(s string, emptyToNull bool) CIText {
+	return CIText(NewString(s, emptyToNull))
+}
+
+// Fold returns the lowercase form of c, used by citext for comparisons. It can
+// be used as a map key.
+func (c CIText) Fold() string {
+	return strings.ToLower(c.String)
+}
+
+// Equals compares c and o case insensitively. Two NULLs are equal.
+func (c CIText) Equals(o CIText) bool {
+	if !c.Valid || !o.Valid {
+		return c.Valid == o.Valid
+	}
+	return c.String == o.String || c.Fold() == o.Fold()
+}
+
+// Scan implements sql.Scanner interface
+func (c *CIText) Scan(src interface{}) error {
+	return (*String)(c).Scan(src)
+}
+
+// Value implements sql/driver.Valuer interface
+func (c CIText) Value() (driver.Value, error) {
+	return String(c).Value()
+}
+
+// MarshalJSON implements Marshaler interface
+func (c CIText) MarshalJSON() ([]byte, error) {
+	return String(c).MarshalJSON()
+}
+
+// UnmarshalJSON implements Unmarshaler interface
+func (c *CIText) UnmarshalJSON(data []byte) error {
+	return (*String)(c).UnmarshalJSON(data)
+}
+
+// CITexts is a slice of CIText for Postgresql `citext[]` type. NULL elements are
+// represented by invalid CITexts.
+type CITexts []CIText
+
+// Scan implements sql.Scanner interface. NULL is scanned as a nil slice.
+func (ls *CITexts) Scan(src interface{}) error {
+	if src == nil {
+		*ls = nil
+		return nil
+	}
+	str, err := bat.UnsafeToString(src)
+	if err != nil {
+		return err
+	}
+	elems, err := ParseArrayDelim(strings.Clone(str), arraySeparator)
+	if err != nil {
+		return err
+	}
+	res := make(CITexts, len(elems))
+	for i, e := range elems {
+		res[i] = CIText(e)
+	}
+	*ls = res
+	return nil
+}
+
+// Value implements sql/driver.Valuer interface. Nil slice is encoded according
+// to NilArrayAsNull.
+func (ls CITexts) Value() (driver.Value, error) {
+	if ls == nil && NilArrayAsNull() {
+		return nil, nil
+	}
+	elems := make([]String, len(ls))
+	for i, e := range ls {
+		elems[i] = String(e)
+	}
+	return FormatArrayDelim(elems, arraySeparator), nil
+}
+
+// Contains checks case insensitively if ls contains s
+func (ls CITexts) Contains(s string) bool {
+	c := NewCIText(s, false)
+	for _, e := range ls {
+		if e.Equals(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Distinct omits every non-first case insensitive occurrence of an element.
+// NULL is kept once.
+func (ls CITexts) Distinct() CITexts {
+	if ls == nil {
+		return nil
+	}
+	result := make(CITexts, 0, len(ls))
+	seen := make(map[string]struct{}, len(ls))
+	null := false
+	for _, e := range ls {
+		if !e.Valid {
+			if !null {
+				null = true
+				result = append(result, e)
+			}
+			continue
+		}
+		k := e.Fold()
+		if _, ok := seen[k]; !ok {
+			seen[k] = struct{}{}
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// Strings returns the valid elements of ls
+func (ls CITexts) Strings() Strings {
+	res := make(Strings, 0, len(ls))
+	for _, e := range ls {
+		if e.Valid {
+			res = append(res, e.String)
+		}
+	}
+	return res
+}
@@ -0,0 +1,64 @@
+package pgt
+
+import (
+	"encoding/json"
+
+	. "github.com/robert-zaremba/checkers"
+	. "gopkg.in/check.v1"
+)
+
+type CITextSuite struct{}
+
+func (suite *CITextSuite) TestEquals(c *C) {
+	a := NewCIText("John.Doe@Example.com", false)
+	c.Check(a.Equals(NewCIText("john.doe@example.COM", false)), IsTrue)
+	c.Check(a.Equals(NewCIText("jane.doe@example.com", false)), IsFalse)
+	c.Check(NewCIText("ŻÓŁW", false).Equals(NewCIText("żółw", false)), IsTrue)
+	c.Check(a.Equals(CIText{}), IsFalse)
+	c.Check(CIText{}.Equals(NewCIText("", true)), IsTrue)
+	c.Check(a.Fold(), Equals, "john.doe@example.com")
+}
+
+func (suite *CITextSuite) TestScanValue(c *C) {
+	var t CIText
+	c.Assert(t.Scan([]byte("Mary")), IsNil)
+	c.Check(t, Equals, NewCIText("Mary", false))
+	v, err := t.Value()
+	c.Check(err, IsNil)
+	c.Check(v, Equals, "Mary")
+	c.Assert(t.Scan(nil), IsNil)
+	c.Check(t.Valid, IsFalse)
+	v, err = t.Value()
+	c.Check(err, IsNil)
+	c.Check(v, IsNil)
+
+	b, err := json.Marshal([]CIText{NewCIText("a", false), {}})
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, `["a",null]`)
+	var res []CIText
+	c.Assert(json.Unmarshal(b, &res), IsNil)
+	c.Check(res, DeepEquals, []CIText{NewCIText("a", false), {}})
+}
+
+func (suite *CITextSuite) TestArray(c *C) {
+	var ls CITexts
+	c.Assert(ls.Scan([]byte(`{Ann,NULL,"ann b","NULL"}`)), IsNil)
+	c.Check(ls, DeepEquals, CITexts{NewCIText("Ann", false), {}, NewCIText("ann b", false), NewCIText("NULL", false)})
+	v, err := ls.Value()
+	c.Check(err, IsNil)
+	c.Check(v, Equals, `{Ann,NULL,"ann b","NULL"}`)
+	c.Check(ls.Strings(), DeepEquals, Strings{"Ann", "ann b", "NULL"})
+
+	c.Check(ls.Contains("ANN"), IsTrue)
+	c.Check(ls.Contains("null"), IsTrue)
+	c.Check(ls.Contains("Bob"), IsFalse)
+
+	ls = CITexts{NewCIText("Ann", false), {}, NewCIText("ANN", false), NewCIText("bob", false), {}}
+	c.Check(ls.Distinct(), DeepEquals, CITexts{NewCIText("Ann", false), {}, NewCIText("bob", false)})
+	c.Check(CITexts(nil).Distinct(), IsNil)
+
+	c.Check(ArrayOverlap(ls, CITexts{NewCIText("BOB", false)}), IsTrue)
+	c.Check(ArrayPosition(ls, NewCIText("ann", false)), Equals, 1)
+	c.Check(ls.Scan(nil), IsNil)
+	c.Check(ls, IsNil)
+}
@@ -0,0 +1,117 @@
+package pgt
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// Collation defines the order of strings, the same way as Postgresql collations
+// used in `ORDER BY` and comparisons. Implementations must be safe for concurrent
+// use.
+type Collation interface {
+	// Compare returns -1, 0 or 1 if a is respectively before, equal or after b
+	Compare(a, b string) int
+}
+
+// CollationC is the byte-wise order of the Postgresql `C`, `POSIX` and
+// `ucs_basic` collations. It's the order used by the Strings methods which
+// don't take a Collation.
+var CollationC Collation = cCollation{}
+
+type cCollation struct{}
+
+func (cCollation) Compare(a, b string) int { return strings.Compare(a, b) }
+
+// unicodeCollation wraps collate.Collator, which is not safe for concurrent use
+type unicodeCollation struct {
+	mu sync.Mutex
+	c  *collate.Collator
+}
+
+// NewUnicodeCollation returns Unicode Collation Algorithm (CLDR) collation for
+// the language, which matches the Postgresql ICU collations, eg: `en-US-x-icu`
+// for language.AmericanEnglish. Strings equal according to the collation are
+// ordered byte-wise, as in Postgresql deterministic collations.
+func NewUnicodeCollation(lang language.Tag, opts ...collate.Option) Collation {
+	return &unicodeCollation{c: collate.New(lang, opts...)}
+}
+
+func (u *unicodeCollation) Compare(a, b string) int {
+	u.mu.Lock()
+	r := u.c.CompareString(a, b)
+	u.mu.Unlock()
+	if r == 0 {
+		return strings.Compare(a, b)
+	}
+	return r
+}
+
+var collations sync.Map // Postgresql collation name -> Collation
+
+// CollationFor returns the collation for Postgresql collation name:
+//   - `C`, `POSIX` and `ucs_basic` return CollationC;
+//   - ICU collations (`und-x-icu`, `en-US-x-icu`, `de-u-co-phonebk-x-icu`) return
+//     Unicode collation for the language;
+//   - libc collations (`en_US.utf8`, `pl_PL.UTF-8`, `en_US`) return Unicode
+//     collation for the language as well. glibc orders most strings the same
+//     way as CLDR, but may differ for punctuation and some scripts.
+func CollationFor(name string) (Collation, error) {
+	if c, ok := collations.Load(name); ok {
+		return c.(Collation), nil
+	}
+	var c Collation
+	switch name {
+	case "C", "POSIX", "ucs_basic":
+		c = CollationC
+	default:
+		tag := strings.TrimSuffix(name, "-x-icu")
+		if i := strings.IndexByte(tag, '.'); i >= 0 { // libc encoding
+			tag = tag[:i]
+		}
+		lang, err := language.Parse(strings.Replace(tag, "_", "-", -1))
+		if err != nil {
+			return nil, fmt.Errorf("Unknown collation %q: %v", name, err)
+		}
+		c = NewUnicodeCollation(lang)
+	}
+	actual, _ := collations.LoadOrStore(name, c)
+	return actual.(Collation), nil
+}
+
+// SortCollate sorts s in place according to the collation
+func (s Strings) SortCollate(c Collation) {
+	slices.SortStableFunc(s, c.Compare)
+}
+
+// IsSortedCollate checks if s is sorted according to the collation
+func (s Strings) IsSortedCollate(c Collation) bool {
+	return slices.IsSortedFunc(s, c.Compare)
+}
+
+// SearchCollate searches for x in s, which must be sorted according to the
+// collation. It returns the position where x is found, or would be inserted,
+// and reports whether x was found.
+func (s Strings) SearchCollate(c Collation, x string) (int, bool) {
+	return slices.BinarySearchFunc(s, x, c.Compare)
+}
+
+// ContainsAllSortedCollate checks if s contains all elements from s2. Both
+// slices must be sorted according to the collation, eg: with `ORDER BY x
+// COLLATE "en-US-x-icu"`.
+func (s Strings) ContainsAllSortedCollate(s2 Strings, c Collation) bool {
+	i := 0
+	for _, x := range s2 {
+		for i < len(s) && c.Compare(s[i], x) < 0 {
+			i++
+		}
+		if i == len(s) || s[i] != x {
+			return false
+		}
+	}
+	return true
+}
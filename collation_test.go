@@ -0,0 +1,80 @@
+package pgt
+
+import (
+	"sync"
+
+	. "github.com/robert-zaremba/checkers"
+	"golang.org/x/text/language"
+	. "gopkg.in/check.v1"
+)
+
+type CollationSuite struct{}
+
+func (suite *CollationSuite) TestCollationC(c *C) {
+	s := Strings{"b", "a", "B", "é", "e", "A"}
+	s.SortCollate(CollationC)
+	c.Check(s, DeepEquals, Strings{"A", "B", "a", "b", "e", "é"})
+	c.Check(s.IsSortedCollate(CollationC), IsTrue)
+}
+
+func (suite *CollationSuite) TestUnicodeCollation(c *C) {
+	en, err := CollationFor("en-US-x-icu")
+	c.Assert(err, IsNil)
+	s := Strings{"b", "a", "B", "é", "e", "A", "f", "Ä"}
+	s.SortCollate(en)
+	c.Check(s, DeepEquals, Strings{"a", "A", "Ä", "b", "B", "e", "é", "f"})
+	c.Check(s.IsSortedCollate(en), IsTrue)
+	c.Check(s.IsSortedCollate(CollationC), IsFalse)
+
+	i, ok := s.SearchCollate(en, "é")
+	c.Check(ok, IsTrue)
+	c.Check(i, Equals, 6)
+	i, ok = s.SearchCollate(en, "c")
+	c.Check(ok, IsFalse)
+	c.Check(i, Equals, 5)
+
+	c.Check(s.ContainsAllSortedCollate(Strings{"A", "b", "b", "é"}, en), IsTrue)
+	c.Check(s.ContainsAllSortedCollate(Strings{"A", "c"}, en), IsFalse)
+	c.Check(s.ContainsAllSortedCollate(Strings{"é", "A"}, en), IsFalse) // not sorted
+
+	// Swedish sorts ä after z
+	sv := NewUnicodeCollation(language.Swedish)
+	s = Strings{"ä", "z", "a"}
+	s.SortCollate(sv)
+	c.Check(s, DeepEquals, Strings{"a", "z", "ä"})
+}
+
+func (suite *CollationSuite) TestCollationFor(c *C) {
+	for _, name := range []string{"C", "POSIX", "ucs_basic"} {
+		coll, err := CollationFor(name)
+		c.Check(err, IsNil)
+		c.Check(coll, Equals, CollationC)
+	}
+	for _, name := range []string{"und-x-icu", "en_US.utf8", "pl_PL.UTF-8", "de-u-co-phonebk-x-icu", "en_US"} {
+		coll, err := CollationFor(name)
+		c.Check(err, IsNil, Commentf(name))
+		c.Check(coll.Compare("a", "B"), Equals, -1, Commentf(name))
+	}
+	c1, _ := CollationFor("en_US.utf8")
+	c2, _ := CollationFor("en_US.utf8")
+	c.Check(c1, Equals, c2)
+	_, err := CollationFor("not a collation!")
+	c.Check(err, NotNil)
+}
+
+func (suite *CollationSuite) TestConcurrentCompare(c *C) {
+	coll := NewUnicodeCollation(language.Polish)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if coll.Compare("ą", "b") != -1 || coll.Compare("a", "a") != 0 {
+					panic("wrong result")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,393 @@
+package pgt
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	bat "github.com/robert-zaremba/go-bat"
+)
+
+// Date represents Postgresql `date` type - a civil date without time and time zone.
+//
+// Unlike Time, Date is not bound to any instant, so it is never shifted across day
+// boundaries by time zone conversions. Year uses astronomical numbering: year 0 is
+// 1 BC, year -1 is 2 BC and so on.
+// When Inf is not Finite then the date represents `infinity` or `-infinity` and the
+// civil date fields are ignored.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+	Inf   Infinity
+	Valid bool
+}
+
+// Infinite dates
+var (
+	DateInfinity    = Date{Inf: PosInfinity, Valid: true}
+	DateNegInfinity = Date{Inf: NegInfinity, Valid: true}
+)
+
+const secondsPerDay = 24 * 60 * 60
+
+// NewDate creates new valid Date. Values outside of their usual ranges are
+// normalized the same way as in time.Date, eg: October 32 converts to November 1.
+func NewDate(year int, month time.Month, day int) Date {
+	return DateOf(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// DateOf returns the date of t in the t location.
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d, Valid: true}
+}
+
+// UTCToday returns the current date in UTC time zone
+func UTCToday() Date {
+	return DateOf(time.Now().UTC())
+}
+
+// ParseDate parses Postgresql date text representation in any of the `DateStyle`
+// output formats (`1997-12-17`, `12/17/1997`, `17.12.1997`, `12-17-1997`, with
+// optional ` BC` suffix) or an infinity literal. The `SQL` and `Postgres`
+// styles field order is set by SetTimeParser.
+func ParseDate(s string) (Date, error) {
+	return CurrentTimeParser().ParseDate(s)
+}
+
+// ParseDate parses date the same way as ParseDate, using the p settings
+func (p TimeParser) ParseDate(s string) (Date, error) {
+	if inf, ok := parseInfinity(s); ok {
+		return Date{Inf: inf, Valid: true}, nil
+	}
+	y, m, d, err := p.parseDatePart(trimBC(s))
+	if err != nil {
+		return Date{}, fmt.Errorf("Can't parse %q as date: %v", s, err)
+	}
+	return Date{Year: y, Month: m, Day: d, Valid: true}, nil
+}
+
+// trimBC removes ` BC` suffix from s and reports if it was present
+func trimBC(s string) (string, bool) {
+	if strings.HasSuffix(s, " BC") {
+		return s[:len(s)-3], true
+	}
+	return s, false
+}
+
+// parseDateFields converts and validates textual year, month and day. It returns
+// astronomical year.
+func parseDateFields(year, month, day string, bc bool) (int, time.Month, int, error) {
+	y, err := parseDigits(year)
+	if err != nil || y < 1 {
+		return 0, 0, 0, errors.New("wrong year")
+	}
+	if bc {
+		y = 1 - y
+	}
+	m, err := parseDigits(month)
+	if err != nil || m < 1 || m > 12 {
+		return 0, 0, 0, errors.New("wrong month")
+	}
+	d, err := parseDigits(day)
+	if err != nil || d < 1 || d > daysIn(time.Month(m), y) {
+		return 0, 0, 0, errors.New("wrong day")
+	}
+	return y, time.Month(m), d, nil
+}
+
+// parseDigits parses non empty, unsigned decimal number
+func parseDigits(s string) (int, error) {
+	if s == "" {
+		return 0, strconv.ErrSyntax
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, strconv.ErrSyntax
+		}
+	}
+	return strconv.Atoi(s)
+}
+
+// daysIn returns number of days in the month of the (astronomical) year
+func daysIn(m time.Month, year int) int {
+	return time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// String returns the ISO representation of the date, the same which is used for
+// Postgresql `DateStyle=ISO`. Empty string is returned for NULL.
+func (d Date) String() string {
+	if !d.Valid {
+		return ""
+	}
+	if d.Inf != Finite {
+		return d.Inf.String()
+	}
+	return string(d.appendText(nil))
+}
+
+func (d Date) appendText(b []byte) []byte {
+	b = d.appendDate(b)
+	if d.Year < 1 {
+		b = append(b, " BC"...)
+	}
+	return b
+}
+
+// appendDate appends the date in ISO format without the BC suffix
+func (d Date) appendDate(b []byte) []byte {
+	y := d.Year
+	if y < 1 {
+		y = 1 - y
+	}
+	b = appendInt(b, y, 4)
+	b = append(b, '-')
+	b = appendInt(b, int(d.Month), 2)
+	b = append(b, '-')
+	return appendInt(b, d.Day, 2)
+}
+
+// appendInt appends decimal representation of non negative x, left padded with
+// zeros to the width
+func appendInt(b []byte, x, width int) []byte {
+	var buf [20]byte
+	i := len(buf)
+	for x >= 10 || width > 1 {
+		i--
+		buf[i] = byte('0' + x%10)
+		x /= 10
+		width--
+	}
+	i--
+	buf[i] = byte('0' + x)
+	return append(b, buf[i:]...)
+}
+
+// IsInfinite returns true if d is `infinity` or `-infinity`
+func (d Date) IsInfinite() bool {
+	return d.Inf != Finite
+}
+
+// Time returns the midnight of the date in the given location.
+// Zero time is returned for NULL and infinite dates.
+func (d Date) Time(loc *time.Location) time.Time {
+	if !d.Valid || d.Inf != Finite {
+		return time.Time{}
+	}
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// Weekday returns the day of the week of the date
+func (d Date) Weekday() time.Weekday {
+	return d.Time(time.UTC).Weekday()
+}
+
+// AddDays returns the date n days after d. NULL and infinite dates are returned
+// unchanged.
+func (d Date) AddDays(n int) Date {
+	return d.AddDate(0, 0, n)
+}
+
+// AddDate returns the date corresponding to adding the given number of years,
+// months, and days to d. It normalizes the result the same way as time.Time.AddDate.
+// NULL and infinite dates are returned unchanged.
+func (d Date) AddDate(years, months, days int) Date {
+	if !d.Valid || d.Inf != Finite {
+		return d
+	}
+	return NewDate(d.Year+years, d.Month+time.Month(months), d.Day+days)
+}
+
+// Sub returns the number of days between u and d (d - u), the same as date
+// subtraction in Postgresql. Both dates must be valid and finite.
+func (d Date) Sub(u Date) int {
+	return int((d.Time(time.UTC).Unix() - u.Time(time.UTC).Unix()) / secondsPerDay)
+}
+
+// Compare returns -1, 0 or 1 if d is respectively before, equal or after u.
+// `-infinity` is before and `infinity` after all finite dates. NULL is sorted
+// after all other values, as Postgresql does in ascending order.
+func (d Date) Compare(u Date) int {
+	if d.Valid != u.Valid {
+		if d.Valid {
+			return -1
+		}
+		return 1
+	}
+	if !d.Valid {
+		return 0
+	}
+	if d.Inf != u.Inf {
+		if d.Inf < u.Inf {
+			return -1
+		}
+		return 1
+	}
+	if d.Inf != Finite {
+		return 0
+	}
+	switch {
+	case d.Year != u.Year:
+		return cmpInt(d.Year, u.Year)
+	case d.Month != u.Month:
+		return cmpInt(int(d.Month), int(u.Month))
+	}
+	return cmpInt(d.Day, u.Day)
+}
+
+func cmpInt(a, b int) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// Before reports whether d is before u
+func (d Date) Before(u Date) bool {
+	return d.Compare(u) < 0
+}
+
+// After reports whether d is after u
+func (d Date) After(u Date) bool {
+	return d.Compare(u) > 0
+}
+
+// Equals reports whether d and u represent the same date
+func (d Date) Equals(u Date) bool {
+	return d.Compare(u) == 0
+}
+
+// Scan implements sql.Scanner interface
+func (d *Date) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Date{}
+		return nil
+	case time.Time:
+		*d = DateOf(v)
+		return nil
+	case []byte, string:
+		s, _ := bat.UnsafeToString(src)
+		parsed, err := ParseDate(s)
+		if err != nil {
+			return err
+		}
+		*d = parsed
+		return nil
+	}
+	return fmt.Errorf("Scan source was not a date, but %T", src)
+}
+
+// Value implements sql/driver.Valuer interface
+func (d Date) Value() (driver.Value, error) {
+	if !d.Valid {
+		return nil, nil
+	}
+	return d.String(), nil
+}
+
+// MarshalText implements encoding.TextMarshaler interface
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler interface. Empty text is
+// decoded as NULL.
+func (d *Date) UnmarshalText(data []byte) (err error) {
+	if len(data) == 0 {
+		*d = Date{}
+		return nil
+	}
+	*d, err = ParseDate(string(data))
+	return err
+}
+
+// MarshalJSON implements Marshaller interface
+func (d Date) MarshalJSON() ([]byte, error) {
+	if !d.Valid {
+		return nullbytes, nil
+	}
+	b := []byte{'"'}
+	if d.Inf != Finite {
+		b = append(b, d.Inf.String()...)
+	} else {
+		b = d.appendText(b)
+	}
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON implements Unmarshaller interface
+func (d *Date) UnmarshalJSON(data []byte) (err error) {
+	if bytes.Equal(data, nullbytes) {
+		*d = Date{}
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return errors.New("expecting string data (value encoded in \"\")")
+	}
+	*d, err = ParseDate(string(data[1 : len(data)-1]))
+	return err
+}
+
+// Dates is a slice of dates for Postgresql `date[]` type. NULL elements are
+// represented by invalid dates.
+type Dates []Date
+
+// Scan implements sql.Scanner interface
+func (ds *Dates) Scan(src interface{}) error {
+	if src == nil {
+		*ds = nil
+		return nil
+	}
+	str, err := bat.UnsafeToString(src)
+	if err != nil {
+		return err
+	}
+	elems, err := parseArray(str)
+	if err != nil {
+		return err
+	}
+	res := make(Dates, len(elems))
+	for i, e := range elems {
+		if e == "NULL" {
+			continue
+		}
+		if res[i], err = ParseDate(e); err != nil {
+			return err
+		}
+	}
+	*ds = res
+	return nil
+}
+
+// Value implements sql/driver.Valuer interface
+func (ds Dates) Value() (driver.Value, error) {
+	if ds == nil && NilArrayAsNull() {
+		return nil, nil
+	}
+	b := []byte{openingArray}
+	for i, d := range ds {
+		if i > 0 {
+			b = append(b, arraySeparator)
+		}
+		switch {
+		case !d.Valid:
+			b = append(b, "NULL"...)
+		case d.Inf != Finite:
+			b = append(b, d.Inf.String()...)
+		case d.Year < 1: // BC suffix contains a space
+			b = append(b, '"')
+			b = append(d.appendText(b), '"')
+		default:
+			b = d.appendText(b)
+		}
+	}
+	return string(append(b, closingArray)), nil
+}
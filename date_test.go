@@ -0,0 +1,109 @@
+package pgt
+
+import (
+	"time"
+
+	. "github.com/robert-zaremba/checkers"
+	. "gopkg.in/check.v1"
+)
+
+type DateSuite struct{}
+
+func (suite *DateSuite) TestParseDate(c *C) {
+	testCases := []struct {
+		in  string
+		out Date
+	}{
+		{"2020-02-29", NewDate(2020, time.February, 29)},
+		{"0044-03-15 BC", Date{Year: -43, Month: time.March, Day: 15, Valid: true}},
+		{"0001-01-01 BC", Date{Year: 0, Month: time.January, Day: 1, Valid: true}},
+		{"10000-01-01", NewDate(10000, time.January, 1)},
+		{"infinity", DateInfinity},
+		{"-infinity", DateNegInfinity},
+	}
+	for _, tc := range testCases {
+		d, err := ParseDate(tc.in)
+		c.Assert(err, IsNil, Comment(tc.in))
+		c.Check(d, Equals, tc.out)
+		c.Check(d.String(), Equals, tc.in)
+	}
+
+	for _, s := range []string{"", "2020-02-30", "2020-13-01", "0000-01-01", "2020/01/01", "2020-1-x"} {
+		_, err := ParseDate(s)
+		c.Check(err, NotNil, Comment(s))
+	}
+}
+
+func (suite *DateSuite) TestDateScan(c *C) {
+	var d Date
+	loc := time.FixedZone("UTC+10", 10*3600)
+	c.Assert(d.Scan(time.Date(2020, 1, 2, 0, 0, 0, 0, loc)), IsNil)
+	c.Check(d, Equals, NewDate(2020, 1, 2))
+
+	c.Assert(d.Scan([]byte("infinity")), IsNil)
+	c.Check(d.IsInfinite(), IsTrue)
+	v, err := d.Value()
+	c.Assert(err, IsNil)
+	c.Check(v, Equals, "infinity")
+
+	c.Assert(d.Scan(nil), IsNil)
+	c.Check(d.Valid, IsFalse)
+	v, err = d.Value()
+	c.Assert(err, IsNil)
+	c.Check(v, IsNil)
+
+	c.Check(d.Scan(12), NotNil)
+}
+
+func (suite *DateSuite) TestDateArithmetic(c *C) {
+	d := NewDate(2020, time.February, 28)
+	c.Check(d.AddDays(1), Equals, NewDate(2020, time.February, 29))
+	c.Check(d.AddDays(2), Equals, NewDate(2020, time.March, 1))
+	c.Check(d.AddDate(1, 0, 0), Equals, NewDate(2021, time.February, 28))
+	c.Check(NewDate(2021, time.January, 1).Sub(d), Equals, 308)
+	c.Check(d.Sub(NewDate(2021, time.January, 1)), Equals, -308)
+	c.Check(DateInfinity.AddDays(3), Equals, DateInfinity)
+
+	c.Check(DateNegInfinity.Before(d), IsTrue)
+	c.Check(DateInfinity.After(d), IsTrue)
+	c.Check(d.Before(d.AddDays(1)), IsTrue)
+	c.Check(d.Compare(Date{}), Equals, -1)
+	c.Check(d.Equals(NewDate(2020, time.February, 28)), IsTrue)
+}
+
+func (suite *DateSuite) TestDateJSON(c *C) {
+	for _, d := range []Date{{}, NewDate(1999, 12, 31), DateInfinity, NewDate(-10, 5, 5)} {
+		var dest Date
+		testMarshalJSON(d, &dest, c)
+		c.Check(dest, Equals, d)
+	}
+	b, err := NewDate(1999, 12, 31).MarshalJSON()
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, `"1999-12-31"`)
+}
+
+func (suite *DateSuite) TestDatesScan(c *C) {
+	ds := Dates{NewDate(2020, 1, 1), {}, DateNegInfinity, NewDate(-43, 3, 15)}
+	v, err := ds.Value()
+	c.Assert(err, IsNil)
+	c.Check(v, Equals, `{2020-01-01,NULL,-infinity,"0044-03-15 BC"}`)
+
+	var dest Dates
+	c.Assert(dest.Scan(v), IsNil)
+	c.Check(dest, DeepEquals, ds)
+
+	c.Assert(dest.Scan("{}"), IsNil)
+	c.Check(dest, HasLen, 0)
+}
+
+func (suite *DateSuite) TestParseDateStyles(c *C) {
+	d := NewDate(1997, time.December, 17)
+	for _, s := range []string{"1997-12-17", "12/17/1997", "17.12.1997", "12-17-1997"} {
+		parsed, err := ParseDate(s)
+		c.Assert(err, IsNil, Comment(s))
+		c.Check(parsed, Equals, d)
+	}
+	parsed, err := ParseDate("03/15/0044 BC")
+	c.Assert(err, IsNil)
+	c.Check(parsed, Equals, NewDate(-43, time.March, 15))
+}
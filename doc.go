@@ -4,6 +4,7 @@
 // * Integer number arrays
 // * String arrays
 // * UUID
+// * Date (civil date with infinity)
 // * Time intervals (duration)
 // * more ...
 //
@@ -0,0 +1,325 @@
+package pgt
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	bat "github.com/robert-zaremba/go-bat"
+)
+
+// EnumSet is a registry of labels of a Postgresql enum type (`CREATE TYPE ... AS
+// ENUM`) in the declaration order. It's safe for concurrent use.
+type EnumSet struct {
+	mu       sync.RWMutex
+	typeName string
+	labels   []string
+	order    map[string]int
+}
+
+// NewEnumSet creates EnumSet of the Postgresql enum type with labels in the
+// declaration order. It panics if a label is empty or duplicated.
+func NewEnumSet(typeName string, labels ...string) *EnumSet {
+	s := &EnumSet{typeName: typeName}
+	if err := s.Set(labels); err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// TypeName returns the Postgresql enum type name
+func (s *EnumSet) TypeName() string {
+	return s.typeName
+}
+
+// Set replaces labels of s, eg: after `ALTER TYPE ... ADD VALUE`.
+func (s *EnumSet) Set(labels []string) error {
+	order := make(map[string]int, len(labels))
+	for i, l := range labels {
+		if l == "" {
+			return fmt.Errorf("Empty label of enum %s", s.typeName)
+		}
+		if _, ok := order[l]; ok {
+			return fmt.Errorf("Duplicated label %q of enum %s", l, s.typeName)
+		}
+		order[l] = i
+	}
+	labels = slices.Clone(labels)
+	s.mu.Lock()
+	s.labels, s.order = labels, order
+	s.mu.Unlock()
+	return nil
+}
+
+// Labels returns labels in the declaration order
+func (s *EnumSet) Labels() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.labels)
+}
+
+// Has checks if label belongs to the enum
+func (s *EnumSet) Has(label string) bool {
+	_, ok := s.Order(label)
+	return ok
+}
+
+// Order returns position of the label in the declaration order
+func (s *EnumSet) Order(label string) (int, bool) {
+	s.mu.RLock()
+	i, ok := s.order[label]
+	s.mu.RUnlock()
+	return i, ok
+}
+
+func (s *EnumSet) check(label string) error {
+	if label != "" && !s.Has(label) {
+		return fmt.Errorf("Invalid input value for enum %s: %q", s.typeName, label)
+	}
+	return nil
+}
+
+// enumLabelsQuery returns labels of the enum type in the declaration order
+const enumLabelsQuery = `SELECT enumlabel FROM pg_enum WHERE enumtypid = $1::regtype ORDER BY enumsortorder`
+
+// StringsQueryFunc executes query with args and returns values of the first
+// column of all rows. It's used to abstract the database driver, eg:
+//
+//	func(query string, args ...interface{}) ([]string, error) {
+//		var res []string
+//		_, err := db.Query(&res, query, args...)
+//		return res, err
+//	}
+type StringsQueryFunc func(query string, args ...interface{}) ([]string, error)
+
+// Load replaces labels of s with labels of the enum type read from `pg_enum`
+func (s *EnumSet) Load(query StringsQueryFunc) error {
+	labels, err := query(enumLabelsQuery, s.typeName)
+	if err != nil {
+		return fmt.Errorf("Can't load labels of enum %s: %v", s.typeName, err)
+	}
+	if len(labels) == 0 {
+		return fmt.Errorf("Enum %s doesn't exist or has no labels", s.typeName)
+	}
+	return s.Set(labels)
+}
+
+// EnumDef is implemented by (usually empty struct) types which define an enum
+// by returning its EnumSet. The methods must work for the zero value.
+type EnumDef interface {
+	EnumSet() *EnumSet
+}
+
+// Enum is a value of the enum defined by D. Empty value represents NULL. Values
+// are validated by Scan, Value and the unmarshallers:
+//
+//	var moods = pgt.NewEnumSet("mood", "sad", "ok", "happy")
+//
+//	type moodDef struct{}
+//
+//	func (moodDef) EnumSet() *pgt.EnumSet { return moods }
+//
+//	type Mood = pgt.Enum[moodDef]
+//
+//	const MoodHappy Mood = "happy"
+type Enum[D EnumDef] string
+
+// ParseEnum returns enum value of the label. Empty label is parsed as NULL.
+func ParseEnum[D EnumDef](label string) (Enum[D], error) {
+	var d D
+	if err := d.EnumSet().check(label); err != nil {
+		return "", err
+	}
+	return Enum[D](label), nil
+}
+
+func (e Enum[D]) set() *EnumSet {
+	var d D
+	return d.EnumSet()
+}
+
+// String returns the enum label
+func (e Enum[D]) String() string { return string(e) }
+
+// IsNull returns true for NULL (empty) value
+func (e Enum[D]) IsNull() bool { return e == "" }
+
+// Valid returns true if e is a label of the enum
+func (e Enum[D]) Valid() bool { return e.set().Has(string(e)) }
+
+// Compare compares enum values by the declaration order, as Postgresql does.
+// NULL and invalid values are sorted last.
+func (e Enum[D]) Compare(o Enum[D]) int {
+	s := e.set()
+	i, ok1 := s.Order(string(e))
+	j, ok2 := s.Order(string(o))
+	switch {
+	case ok1 && ok2:
+		return cmpInt(i, j)
+	case ok1 != ok2:
+		if ok1 {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(string(e), string(o))
+}
+
+// Scan implements sql.Scanner interface
+func (e *Enum[D]) Scan(src interface{}) error {
+	if src == nil {
+		*e = ""
+		return nil
+	}
+	bs, err := bat.UnsafeToBytes(src)
+	if err != nil {
+		return err
+	}
+	if len(bs) == 0 {
+		return fmt.Errorf("Invalid input value for enum %s: \"\"", e.set().TypeName())
+	}
+	return e.UnmarshalText(bs)
+}
+
+// Value implements sql/driver.Valuer interface. NULL is encoded as nil.
+func (e Enum[D]) Value() (driver.Value, error) {
+	if e == "" {
+		return nil, nil
+	}
+	if err := e.set().check(string(e)); err != nil {
+		return nil, err
+	}
+	return string(e), nil
+}
+
+// MarshalText implements encoding.TextMarshaler interface
+func (e Enum[D]) MarshalText() ([]byte, error) {
+	if err := e.set().check(string(e)); err != nil {
+		return nil, err
+	}
+	return []byte(e), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler interface. Empty text is
+// decoded as NULL.
+func (e *Enum[D]) UnmarshalText(data []byte) error {
+	label := string(data)
+	if err := e.set().check(label); err != nil {
+		return err
+	}
+	*e = Enum[D](label)
+	return nil
+}
+
+// MarshalJSON implements Marshaler interface. NULL is encoded as null.
+func (e Enum[D]) MarshalJSON() ([]byte, error) {
+	if e == "" {
+		return nullbytes, nil
+	}
+	if err := e.set().check(string(e)); err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(e))
+}
+
+// UnmarshalJSON implements Unmarshaler interface
+func (e *Enum[D]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, nullbytes) {
+		*e = ""
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return e.UnmarshalText([]byte(s))
+}
+
+// MarshalYAML implements Marshaler interface of YAML. NULL is encoded as null.
+func (e Enum[D]) MarshalYAML() (interface{}, error) {
+	if e == "" {
+		return nil, nil
+	}
+	if err := e.set().check(string(e)); err != nil {
+		return nil, err
+	}
+	return string(e), nil
+}
+
+// UnmarshalYAML implements Unmarshaler interface of YAML
+func (e *Enum[D]) UnmarshalYAML(unmarshaler func(interface{}) error) error {
+	var s *string
+	if err := unmarshaler(&s); err != nil {
+		return err
+	}
+	if s == nil {
+		*e = ""
+		return nil
+	}
+	return e.UnmarshalText([]byte(*s))
+}
+
+// Enums is a slice of enum values for Postgresql enum arrays. NULL elements are
+// represented by empty values.
+type Enums[D EnumDef] []Enum[D]
+
+// Scan implements sql.Scanner interface. NULL is scanned as a nil slice.
+func (ls *Enums[D]) Scan(src interface{}) error {
+	if src == nil {
+		*ls = nil
+		return nil
+	}
+	bs, err := bat.UnsafeToBytes(src)
+	if err != nil {
+		return err
+	}
+	res := Enums[D]{}
+	var d ArrayDecoder
+	d.Reset(bs, arraySeparator)
+	for d.Next() {
+		var e Enum[D]
+		if !d.IsNull() {
+			if err = e.UnmarshalText(d.Elem()); err != nil {
+				return err
+			}
+		}
+		res = append(res, e)
+	}
+	if err = d.Err(); err != nil {
+		return err
+	}
+	*ls = res
+	return nil
+}
+
+// Value implements sql/driver.Valuer interface. Nil slice is encoded according
+// to NilArrayAsNull.
+func (ls Enums[D]) Value() (driver.Value, error) {
+	if ls == nil && NilArrayAsNull() {
+		return nil, nil
+	}
+	b := []byte{openingArray}
+	for i, e := range ls {
+		if i > 0 {
+			b = append(b, arraySeparator)
+		}
+		if e == "" {
+			b = append(b, "NULL"...)
+			continue
+		}
+		if err := e.set().check(string(e)); err != nil {
+			return nil, err
+		}
+		b = appendArrayElem(b, string(e), arraySeparator)
+	}
+	return bat.UnsafeByteArrayToStr(append(b, closingArray)), nil
+}
+
+// Sort sorts ls in place by the declaration order, NULLs last
+func (ls Enums[D]) Sort() {
+	slices.SortStableFunc(ls, Enum[D].Compare)
+}
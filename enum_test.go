@@ -0,0 +1,150 @@
+package pgt
+
+import (
+	"encoding/json"
+	"errors"
+
+	. "github.com/robert-zaremba/checkers"
+	. "gopkg.in/check.v1"
+)
+
+type EnumSuite struct{}
+
+var testMoods = NewEnumSet("mood", "sad", "ok", "happy")
+
+type testMoodDef struct{}
+
+func (testMoodDef) EnumSet() *EnumSet { return testMoods }
+
+type testMood = Enum[testMoodDef]
+
+const (
+	moodSad   testMood = "sad"
+	moodOk    testMood = "ok"
+	moodHappy testMood = "happy"
+)
+
+func (suite *EnumSuite) TestEnumSet(c *C) {
+	c.Check(testMoods.Labels(), DeepEquals, []string{"sad", "ok", "happy"})
+	c.Check(testMoods.Has("ok"), IsTrue)
+	c.Check(testMoods.Has("OK"), IsFalse)
+	i, ok := testMoods.Order("happy")
+	c.Check(ok, IsTrue)
+	c.Check(i, Equals, 2)
+
+	s := NewEnumSet("x", "a")
+	c.Check(s.Set([]string{"a", "b", "a"}), ErrorMatches, `Duplicated label "a" of enum x`)
+	c.Check(s.Set([]string{"a", ""}), NotNil)
+	c.Check(s.Labels(), DeepEquals, []string{"a"})
+	c.Check(func() { NewEnumSet("x", "a", "a") }, PanicMatches, ".*Duplicated.*")
+}
+
+func (suite *EnumSuite) TestLoad(c *C) {
+	s := NewEnumSet("mood", "sad")
+	var query string
+	var args []interface{}
+	err := s.Load(func(q string, a ...interface{}) ([]string, error) {
+		query, args = q, a
+		return []string{"sad", "ok", "happy", "ecstatic"}, nil
+	})
+	c.Assert(err, IsNil)
+	c.Check(query, Equals, enumLabelsQuery)
+	c.Check(args, DeepEquals, []interface{}{"mood"})
+	c.Check(s.Labels(), DeepEquals, []string{"sad", "ok", "happy", "ecstatic"})
+
+	err = s.Load(func(string, ...interface{}) ([]string, error) { return nil, errors.New("conn closed") })
+	c.Check(err, ErrorMatches, "Can't load labels of enum mood: conn closed")
+	err = s.Load(func(string, ...interface{}) ([]string, error) { return nil, nil })
+	c.Check(err, NotNil)
+	c.Check(s.Labels(), HasLen, 4)
+}
+
+func (suite *EnumSuite) TestScanValue(c *C) {
+	var m testMood
+	c.Assert(m.Scan([]byte("happy")), IsNil)
+	c.Check(m, Equals, moodHappy)
+	c.Check(m.Valid(), IsTrue)
+	c.Check(m.Scan("angry"), ErrorMatches, `Invalid input value for enum mood: "angry"`)
+	c.Check(m.Scan(""), NotNil)
+	c.Assert(m.Scan(nil), IsNil)
+	c.Check(m.IsNull(), IsTrue)
+	c.Check(m.Valid(), IsFalse)
+
+	v, err := moodOk.Value()
+	c.Check(err, IsNil)
+	c.Check(v, Equals, "ok")
+	v, err = testMood("").Value()
+	c.Check(err, IsNil)
+	c.Check(v, IsNil)
+	_, err = testMood("angry").Value()
+	c.Check(err, NotNil)
+
+	m, err = ParseEnum[testMoodDef]("sad")
+	c.Check(err, IsNil)
+	c.Check(m, Equals, moodSad)
+	_, err = ParseEnum[testMoodDef]("Sad")
+	c.Check(err, NotNil)
+}
+
+func (suite *EnumSuite) TestMarshal(c *C) {
+	type doc struct {
+		Mood  testMood
+		Other testMood
+	}
+	b, err := json.Marshal(doc{Mood: moodOk})
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, `{"Mood":"ok","Other":null}`)
+	var d doc
+	c.Assert(json.Unmarshal(b, &d), IsNil)
+	c.Check(d, Equals, doc{Mood: moodOk})
+	c.Check(json.Unmarshal([]byte(`{"Mood":"angry"}`), &d), NotNil)
+	_, err = json.Marshal(doc{Mood: "angry"})
+	c.Check(err, NotNil)
+
+	// map keys use the text encoding
+	b, err = json.Marshal(map[testMood]int{moodHappy: 1})
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, `{"happy":1}`)
+	var m map[testMood]int
+	c.Check(json.Unmarshal([]byte(`{"angry":1}`), &m), NotNil)
+
+	y, err := moodSad.MarshalYAML()
+	c.Check(err, IsNil)
+	c.Check(y, Equals, "sad")
+	y, err = testMood("").MarshalYAML()
+	c.Check(err, IsNil)
+	c.Check(y, IsNil)
+	var e testMood = moodSad
+	c.Assert(e.UnmarshalYAML(func(interface{}) error { return nil }), IsNil)
+	c.Check(e.IsNull(), IsTrue)
+	c.Assert(e.UnmarshalYAML(func(v interface{}) error {
+		s := "happy"
+		*(v.(**string)) = &s
+		return nil
+	}), IsNil)
+	c.Check(e, Equals, moodHappy)
+}
+
+func (suite *EnumSuite) TestOrderAndArrays(c *C) {
+	c.Check(moodSad.Compare(moodHappy), Equals, -1)
+	c.Check(moodHappy.Compare(moodOk), Equals, 1)
+	c.Check(moodOk.Compare(moodOk), Equals, 0)
+	c.Check(moodHappy.Compare(""), Equals, -1)
+
+	ls := Enums[testMoodDef]{moodHappy, "", moodSad, moodOk}
+	ls.Sort()
+	c.Check(ls, DeepEquals, Enums[testMoodDef]{moodSad, moodOk, moodHappy, ""})
+
+	v, err := ls.Value()
+	c.Assert(err, IsNil)
+	c.Check(v, Equals, "{sad,ok,happy,NULL}")
+	var dest Enums[testMoodDef]
+	c.Assert(dest.Scan(v), IsNil)
+	c.Check(dest, DeepEquals, ls)
+	c.Check(dest.Scan("{sad,angry}"), NotNil)
+	_, err = Enums[testMoodDef]{"angry"}.Value()
+	c.Check(err, NotNil)
+
+	c.Check(ArrayOverlap(ls, Enums[testMoodDef]{""}), IsFalse)
+	c.Check(ArrayPosition(ls, ""), Equals, 4)
+}
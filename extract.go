@@ -0,0 +1,136 @@
+package pgt
+
+import (
+	"iter"
+)
+
+// Iterator is a pull style iterator, commonly implemented by query result
+// wrappers. Next advances the iterator and must be called before the first Get.
+type Iterator[T any] interface {
+	Get() T
+	Next() bool
+}
+
+// Seq converts Iterator into iter.Seq
+func Seq[T any](it Iterator[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for it.Next() {
+			if !yield(it.Get()) {
+				return
+			}
+		}
+	}
+}
+
+// Map returns a sequence of f results for each element of seq. It's useful to
+// extract a column from a sequence of rows:
+//
+//	ids := pgt.ExtractInts(pgt.Map(slices.Values(users), func(u User) int64 { return u.GroupID }))
+func Map[R, T any](seq iter.Seq[R], f func(R) T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for r := range seq {
+			if !yield(f(r)) {
+				return
+			}
+		}
+	}
+}
+
+// Unique returns unique elements of seq, preserving the first seen order.
+func Unique[T comparable](seq iter.Seq[T]) []T {
+	return uniqueFunc(seq, func(v T) T { return v }, 0)
+}
+
+// UniqueFunc returns elements of seq with unique keys, preserving the first
+// seen order.
+func UniqueFunc[T any, K comparable](seq iter.Seq[T], key func(T) K) []T {
+	return uniqueFunc(seq, key, 0)
+}
+
+func uniqueFunc[T any, K comparable](seq iter.Seq[T], key func(T) K, capacity int) []T {
+	set := make(map[K]struct{}, capacity)
+	res := make([]T, 0, capacity)
+	for v := range seq {
+		k := key(v)
+		if _, ok := set[k]; !ok {
+			set[k] = struct{}{}
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// filter returns a sequence of seq elements for which keep returns true
+func filter[T any](seq iter.Seq[T], keep func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if keep(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// The following functions return unique, non NULL elements of a sequence, in
+// the first seen order, as pgt array types which can be directly bound as
+// query arguments, eg: `WHERE id = ANY($1)`.
+// NULLs are skipped because they never match `= ANY`.
+
+// ExtractInts returns unique integers from seq
+func ExtractInts(seq iter.Seq[int64]) Ints {
+	return Unique(seq)
+}
+
+// ExtractFloat64s returns unique floats from seq
+func ExtractFloat64s(seq iter.Seq[float64]) Float64s {
+	return Unique(seq)
+}
+
+// ExtractStrings returns unique strings from seq
+func ExtractStrings(seq iter.Seq[string]) Strings {
+	return Unique(seq)
+}
+
+// ExtractUUIDsSeq returns unique, non empty UUIDs from seq
+func ExtractUUIDsSeq(seq iter.Seq[UUID]) UUIDs {
+	return UniqueFunc(filter(seq, func(u UUID) bool { return !u.Empty() }), UUID.Fixed)
+}
+
+// ExtractFixedUUIDs returns unique UUIDs from seq. The nil UUID is not NULL, so
+// it is returned as well.
+func ExtractFixedUUIDs(seq iter.Seq[FixedUUID]) FixedUUIDs {
+	return Unique(seq)
+}
+
+// ExtractDates returns unique, valid dates from seq
+func ExtractDates(seq iter.Seq[Date]) Dates {
+	return UniqueFunc(filter(seq, func(d Date) bool { return d.Valid }), dateKeyOf)
+}
+
+// dateKeyOf returns comparable key, equal for the same dates
+func dateKeyOf(d Date) Date {
+	if d.Inf != Finite {
+		return Date{Inf: d.Inf, Valid: true}
+	}
+	return d
+}
+
+type timeKey struct {
+	sec  int64
+	nsec int
+	inf  Infinity
+}
+
+// timeKeyOf returns comparable key, equal for the same instants
+func timeKeyOf(t InfTime) timeKey {
+	if t.Inf != Finite {
+		return timeKey{inf: t.Inf}
+	}
+	return timeKey{t.Unix(), t.Nanosecond(), Finite}
+}
+
+// ExtractTimes returns unique, valid times from seq. Times are compared as
+// instants, independently of their locations.
+func ExtractTimes(seq iter.Seq[InfTime]) Times {
+	return UniqueFunc(filter(seq, func(t InfTime) bool { return t.Valid }), timeKeyOf)
+}
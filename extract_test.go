@@ -0,0 +1,63 @@
+package pgt
+
+import (
+	"slices"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type ExtractSuite struct{}
+
+type extractRow struct {
+	ID    int64
+	Name  string
+	Group UUID
+	At    InfTime
+}
+
+func (suite *ExtractSuite) TestSeqFromIterator(c *C) {
+	a, b := RandomUUID(), RandomUUID()
+	var res []UUID
+	for u := range Seq[UUID](&uuidSliceIterator{ls: UUIDs{a, b, a}}) {
+		res = append(res, u)
+		if len(res) == 2 {
+			break
+		}
+	}
+	c.Check(res, DeepEquals, []UUID{a, b})
+}
+
+func (suite *ExtractSuite) TestUnique(c *C) {
+	c.Check(Unique(slices.Values([]int{3, 1, 3, 2, 1})), DeepEquals, []int{3, 1, 2})
+	c.Check(Unique(slices.Values([]int(nil))), HasLen, 0)
+	c.Check(UniqueFunc(slices.Values([]string{"a", "B", "A", "b"}), func(s string) byte { return s[0] | 0x20 }),
+		DeepEquals, []string{"a", "B"})
+}
+
+func (suite *ExtractSuite) TestExtractFromRows(c *C) {
+	g1, g2 := RandomUUID(), RandomUUID()
+	t1 := time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)
+	warsaw := time.FixedZone("CET", 3600)
+	rows := []extractRow{
+		{1, "a", g1, NewInfTime(t1)},
+		{2, "b", nil, InfTime{}},
+		{1, "a", g2, InfTime{Time: Time{t1.In(warsaw), true}}},
+		{3, "c", g1, TimeInfinity},
+	}
+	seq := slices.Values(rows)
+
+	c.Check(ExtractInts(Map(seq, func(r extractRow) int64 { return r.ID })), DeepEquals, Ints{1, 2, 3})
+	c.Check(ExtractStrings(Map(seq, func(r extractRow) string { return r.Name })), DeepEquals, Strings{"a", "b", "c"})
+	c.Check(ExtractUUIDsSeq(Map(seq, func(r extractRow) UUID { return r.Group })), DeepEquals, UUIDs{g1, g2})
+	c.Check(ExtractFixedUUIDs(Map(seq, func(r extractRow) FixedUUID { return r.Group.Fixed() })),
+		DeepEquals, FixedUUIDs{g1.Fixed(), {}, g2.Fixed()})
+	times := ExtractTimes(Map(seq, func(r extractRow) InfTime { return r.At }))
+	c.Check(times, DeepEquals, Times{NewInfTime(t1), TimeInfinity})
+}
+
+func (suite *ExtractSuite) TestExtractDates(c *C) {
+	d := NewDate(2020, 1, 2)
+	res := ExtractDates(slices.Values([]Date{d, {}, DateInfinity, d, DateInfinity}))
+	c.Check(res, DeepEquals, Dates{d, DateInfinity})
+}
@@ -0,0 +1,193 @@
+package pgt
+
+import (
+	"math"
+	"math/big"
+	"math/bits"
+	"strconv"
+
+	bat "github.com/robert-zaremba/go-bat"
+)
+
+// float64pow10 contains powers of 10 exactly representable as float64
+var float64pow10 = [...]float64{
+	1e0, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10,
+	1e11, 1e12, 1e13, 1e14, 1e15, 1e16, 1e17, 1e18, 1e19, 1e20, 1e21, 1e22,
+}
+
+// Range of decimal exponents covered by pow10Mantissas
+const (
+	pow10MinExp = -128
+	pow10MaxExp = 127
+)
+
+// pow10Mantissas contains 128 bits mantissas of powers of 10 from pow10MinExp
+// to pow10MaxExp: the most significant bits of 10^e, normalized (the highest
+// bit is set) and truncated, as high and low 64 bits.
+var pow10Mantissas = func() (res [pow10MaxExp - pow10MinExp + 1][2]uint64) {
+	mask := new(big.Int).SetUint64(math.MaxUint64)
+	for e := pow10MinExp; e <= pow10MaxExp; e++ {
+		p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(e))), nil)
+		m := new(big.Int)
+		if e >= 0 {
+			if n := p.BitLen(); n > 128 {
+				m.Rsh(p, uint(n-128))
+			} else {
+				m.Lsh(p, uint(128-n))
+			}
+		} else {
+			// 2^(n-1) < 10^-e < 2^n, so the quotient has exactly 128 bits
+			m.Lsh(big.NewInt(1), uint(p.BitLen()+127))
+			m.Quo(m, p)
+		}
+		res[e-pow10MinExp][1] = new(big.Int).Rsh(m, 64).Uint64()
+		res[e-pow10MinExp][0] = new(big.Int).And(m, mask).Uint64()
+	}
+	return
+}()
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// parseFloatBytes parses float from b without copying it. Decimal numbers with
+// up to 19 significant digits, which includes the shortest representations of
+// all `float4` and `float8` values, are converted exactly: with one float
+// multiplication or division when the mantissa and the exponent are small,
+// otherwise with the Eisel-Lemire algorithm. Other numbers (and the rare cases
+// which Eisel-Lemire can't decide) are parsed with strconv.ParseFloat.
+func parseFloatBytes(b []byte) (float64, error) {
+	i := 0
+	neg := false
+	if len(b) > 0 && (b[0] == '-' || b[0] == '+') {
+		neg = b[0] == '-'
+		i++
+	}
+	var mantissa uint64
+	var digits, exp int
+	var sawDigit, sawDot bool
+	for ; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case c >= '0' && c <= '9':
+			sawDigit = true
+			if mantissa == 0 && c == '0' {
+				if sawDot {
+					exp--
+				}
+				continue
+			}
+			if digits++; digits > 19 {
+				return parseFloatFallback(b)
+			}
+			mantissa = mantissa*10 + uint64(c-'0')
+			if sawDot {
+				exp--
+			}
+		case c == '.' && !sawDot:
+			sawDot = true
+		default:
+			goto exponent
+		}
+	}
+exponent:
+	if i < len(b) && (b[i] == 'e' || b[i] == 'E') && sawDigit {
+		e, err := strconv.Atoi(bat.UnsafeByteArrayToStr(b[i+1:]))
+		if err != nil || e > 1000 || e < -1000 {
+			return parseFloatFallback(b)
+		}
+		exp += e
+		i = len(b)
+	}
+	if i != len(b) || !sawDigit {
+		return parseFloatFallback(b)
+	}
+	if mantissa <= 1<<53 && exp >= -22 && exp <= 22 {
+		f := float64(mantissa)
+		if exp < 0 {
+			f /= float64pow10[-exp]
+		} else {
+			f *= float64pow10[exp]
+		}
+		if neg {
+			f = -f
+		}
+		return f, nil
+	}
+	if f, ok := eiselLemire(mantissa, exp, neg); ok {
+		return f, nil
+	}
+	return parseFloatFallback(b)
+}
+
+// eiselLemire converts mantissa * 10^exp to the nearest float64 using the
+// algorithm by Daniel Lemire (https://arxiv.org/abs/2101.11408), as in the
+// strconv package. It returns false if the result can't be decided exactly, is
+// subnormal, infinite or exp is out of the pow10Mantissas range.
+func eiselLemire(mantissa uint64, exp int, neg bool) (float64, bool) {
+	if mantissa == 0 {
+		if neg {
+			return math.Copysign(0, -1), true
+		}
+		return 0, true
+	}
+	if exp < pow10MinExp || exp > pow10MaxExp {
+		return 0, false
+	}
+	pow := pow10Mantissas[exp-pow10MinExp]
+
+	// normalization
+	clz := bits.LeadingZeros64(mantissa)
+	mantissa <<= uint(clz)
+	const float64ExponentBias = 1023
+	retExp2 := uint64(217706*exp>>16+64+float64ExponentBias) - uint64(clz)
+
+	// multiplication, with a wider approximation if the truncated product is
+	// too close to the rounding boundary
+	xHi, xLo := bits.Mul64(mantissa, pow[1])
+	if xHi&0x1ff == 0x1ff && xLo+mantissa < mantissa {
+		yHi, yLo := bits.Mul64(mantissa, pow[0])
+		mergedHi, mergedLo := xHi, xLo+yHi
+		if mergedLo < xLo {
+			mergedHi++
+		}
+		if mergedHi&0x1ff == 0x1ff && mergedLo+1 == 0 && yLo+mantissa < mantissa {
+			return 0, false
+		}
+		xHi, xLo = mergedHi, mergedLo
+	}
+
+	// shifting to 54 bits
+	msb := xHi >> 63
+	retMantissa := xHi >> (msb + 9)
+	retExp2 -= 1 ^ msb
+
+	// half-way ambiguity
+	if xLo == 0 && xHi&0x1ff == 0 && retMantissa&3 == 1 {
+		return 0, false
+	}
+
+	// from 54 to 53 bits
+	retMantissa += retMantissa & 1
+	retMantissa >>= 1
+	if retMantissa>>53 > 0 {
+		retMantissa >>= 1
+		retExp2++
+	}
+	// subnormal or infinite
+	if retExp2-1 >= 0x7ff-1 {
+		return 0, false
+	}
+	retBits := retExp2<<52 | retMantissa&(1<<52-1)
+	if neg {
+		retBits |= 1 << 63
+	}
+	return math.Float64frombits(retBits), true
+}
+
+func parseFloatFallback(b []byte) (float64, error) {
+	return strconv.ParseFloat(bat.UnsafeByteArrayToStr(b), 64)
+}
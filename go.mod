@@ -1,17 +1,30 @@
 module github.com/robert-zaremba/go-pgt
 
-go 1.13
+go 1.23
 
 require (
 	github.com/elgs/gostrgen v0.0.0-20161222160715-9d61ae07eeae
 	github.com/go-pg/pg v8.0.6+incompatible
-	github.com/jinzhu/inflection v1.0.0 // indirect
-	github.com/onsi/ginkgo v1.12.0 // indirect
-	github.com/onsi/gomega v1.9.0 // indirect
 	github.com/pborman/uuid v1.2.0
 	github.com/robert-zaremba/checkers v1.0.1
 	github.com/robert-zaremba/errstack v1.0.2
 	github.com/robert-zaremba/go-bat v1.0.1
+	golang.org/x/text v0.21.0
 	gopkg.in/check.v1 v1.0.0-20200227125254-8fa46927fb4f
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/facebookgo/stack v0.0.0-20160209184415-751773369052 // indirect
+	github.com/google/uuid v1.0.0 // indirect
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/kardianos/osext v0.0.0-20190222173326-2bc1f35cddc0 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/mozillazg/go-unidecode v0.1.1 // indirect
+	github.com/niemeyer/pretty v0.0.0-20200227124842-a10e7caefd8e // indirect
+	github.com/onsi/ginkgo v1.12.0 // indirect
+	github.com/onsi/gomega v1.9.0 // indirect
+	github.com/spaolacci/murmur3 v1.1.0 // indirect
+	golang.org/x/crypto v0.0.0-20180910181607-0e37d006457b // indirect
 	mellium.im/sasl v0.2.1 // indirect
 )
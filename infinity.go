@@ -0,0 +1,40 @@
+package pgt
+
+// Infinity represents the special `infinity` and `-infinity` values supported by
+// Postgresql date and timestamp types.
+type Infinity int8
+
+// Valid Infinity values
+const (
+	NegInfinity Infinity = -1
+	Finite      Infinity = 0
+	PosInfinity Infinity = 1
+)
+
+const (
+	infinityStr    = "infinity"
+	negInfinityStr = "-infinity"
+)
+
+// String returns the Postgresql representation of the infinity value or an empty
+// string for Finite.
+func (i Infinity) String() string {
+	switch i {
+	case PosInfinity:
+		return infinityStr
+	case NegInfinity:
+		return negInfinityStr
+	}
+	return ""
+}
+
+// parseInfinity checks if s is a Postgresql infinity literal
+func parseInfinity(s string) (Infinity, bool) {
+	switch s {
+	case infinityStr, "+" + infinityStr:
+		return PosInfinity, true
+	case negInfinityStr:
+		return NegInfinity, true
+	}
+	return Finite, false
+}
@@ -11,8 +11,26 @@ func Test(t *testing.T) { TestingT(t) }
 
 func init() {
 	Suite(&TimeSuite{})
+	Suite(&InfTimeSuite{})
 	Suite(&ArraySuite{})
 	Suite(&UUIDSuite{})
 	Suite(&StringSuite{})
 	Suite(&BigIntS{})
+	Suite(&DateSuite{})
+	Suite(&TimeOfDaySuite{})
+	Suite(&TimeArraySuite{})
+	Suite(&ZonedTimeSuite{})
+	Suite(&BinarySuite{})
+	Suite(&FixedUUIDSuite{})
+	Suite(&UUIDSetSuite{})
+	Suite(&UUIDEncodingSuite{})
+	Suite(&ExtractSuite{})
+	Suite(&ArrayDecoderSuite{})
+	Suite(&ArrayOpsSuite{})
+	Suite(&CollationSuite{})
+	Suite(&CITextSuite{})
+	Suite(&EnumSuite{})
+	Suite(&RecordSuite{})
+	Suite(&TextSearchSuite{})
+	Suite(&LTreeSuite{})
 }
@@ -0,0 +1,477 @@
+package pgt
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	bat "github.com/robert-zaremba/go-bat"
+)
+
+// Limits of the ltree type
+const (
+	ltreeMaxLabelLen = 1000
+	ltreeMaxLevels   = 1<<16 - 1
+)
+
+// LTree is a path of the Postgresql `ltree` type: labels separated by dots,
+// eg: `Top.Science.Astronomy`. Labels consist of letters, digits, `_` and `-`.
+// Empty LTree is the empty path (of 0 levels). Values created with ParseLTree
+// and NewLTree are valid.
+type LTree string
+
+// checkLTreeLabel checks if label is a valid ltree label
+func checkLTreeLabel(label string) error {
+	if label == "" {
+		return errors.New("empty label")
+	}
+	if n := utf8.RuneCountInString(label); n > ltreeMaxLabelLen {
+		return fmt.Errorf("label of %d characters is longer than %d", n, ltreeMaxLabelLen)
+	}
+	for i, r := range label {
+		if r != '_' && r != '-' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return fmt.Errorf("wrong character %q in label %q at position %d", r, label, i+1)
+		}
+	}
+	return nil
+}
+
+// ParseLTree validates the text representation of ltree
+func ParseLTree(src string) (LTree, error) {
+	if src == "" {
+		return "", nil
+	}
+	level := 0
+	for _, label := range strings.Split(src, ".") {
+		if level++; level > ltreeMaxLevels {
+			return "", fmt.Errorf("Can't parse ltree: more than %d levels", ltreeMaxLevels)
+		}
+		if err := checkLTreeLabel(label); err != nil {
+			return "", fmt.Errorf("Can't parse ltree: %v at level %d", err, level)
+		}
+	}
+	return LTree(src), nil
+}
+
+// NewLTree creates LTree of the labels
+func NewLTree(labels ...string) (LTree, error) {
+	if len(labels) > ltreeMaxLevels {
+		return "", fmt.Errorf("Can't create ltree: more than %d levels", ltreeMaxLevels)
+	}
+	for i, l := range labels {
+		if err := checkLTreeLabel(l); err != nil {
+			return "", fmt.Errorf("Can't create ltree: %v at level %d", err, i+1)
+		}
+	}
+	return LTree(strings.Join(labels, ".")), nil
+}
+
+// String returns t as a string
+func (t LTree) String() string { return string(t) }
+
+// Labels returns labels of t
+func (t LTree) Labels() []string {
+	if t == "" {
+		return []string{}
+	}
+	return strings.Split(string(t), ".")
+}
+
+// NLevel implements `nlevel(t)`: it returns the number of labels of t
+func (t LTree) NLevel() int {
+	if t == "" {
+		return 0
+	}
+	return strings.Count(string(t), ".") + 1
+}
+
+// IsAncestorOf implements the `t @> o` operator: it returns true if t is an
+// ancestor of o or is equal to o. The empty path is the ancestor of all paths.
+func (t LTree) IsAncestorOf(o LTree) bool {
+	return t == "" || t == o ||
+		len(o) > len(t) && o[len(t)] == '.' && strings.HasPrefix(string(o), string(t))
+}
+
+// IsDescendantOf implements the `t <@ o` operator: it returns true if t is a
+// descendant of o or is equal to o.
+func (t LTree) IsDescendantOf(o LTree) bool {
+	return o.IsAncestorOf(t)
+}
+
+// Subpath implements `subpath(t, offset, length)`: it returns length labels of t
+// starting at offset (from 0). Negative offset is counted from the end of t.
+// Negative length leaves that many labels off the end of t. As in Postgresql,
+// an error is returned if offset is out of t.
+func (t LTree) Subpath(offset, length int) (LTree, error) {
+	labels := t.Labels()
+	start := offset
+	if start < 0 {
+		start += len(labels)
+	}
+	end := start + length
+	if length < 0 {
+		end = len(labels) + length
+	}
+	res, ok := subltree(labels, start, end)
+	if !ok {
+		return "", fmt.Errorf("Invalid positions of subpath(%q, %d, %d)", t, offset, length)
+	}
+	return res, nil
+}
+
+// Subltree implements `subltree(t, start, end)`: it returns labels of t from
+// start (inclusive) to end (exclusive), counted from 0.
+func (t LTree) Subltree(start, end int) (LTree, error) {
+	res, ok := subltree(t.Labels(), start, end)
+	if !ok {
+		return "", fmt.Errorf("Invalid positions of subltree(%q, %d, %d)", t, start, end)
+	}
+	return res, nil
+}
+
+func subltree(labels []string, start, end int) (LTree, bool) {
+	if start < 0 || end < 0 || start >= len(labels) || start > end {
+		return "", false
+	}
+	return LTree(strings.Join(labels[start:min(end, len(labels))], ".")), true
+}
+
+// Concat implements the `t || o` operator
+func (t LTree) Concat(o LTree) LTree {
+	if t == "" || o == "" {
+		return t + o
+	}
+	return t + "." + o
+}
+
+// LCA implements `lca(trees...)`: it returns the longest common ancestor of
+// the paths, which doesn't include the last label of any of them. It returns
+// false (SQL NULL) if there are no paths or one of them is empty.
+func LCA(trees ...LTree) (LTree, bool) {
+	if len(trees) == 0 {
+		return "", false
+	}
+	res := trees[0].Labels()
+	if len(res) == 0 {
+		return "", false
+	}
+	res = res[:len(res)-1]
+	for _, t := range trees[1:] {
+		labels := t.Labels()
+		if len(labels) == 0 {
+			return "", false
+		}
+		labels = labels[:len(labels)-1]
+		i := 0
+		for i < len(res) && i < len(labels) && res[i] == labels[i] {
+			i++
+		}
+		res = res[:i]
+	}
+	return LTree(strings.Join(res, ".")), true
+}
+
+// Scan implements sql.Scanner interface. NULL is scanned as the empty path,
+// use NullLTree to distinguish them.
+func (t *LTree) Scan(src interface{}) error {
+	if src == nil {
+		*t = ""
+		return nil
+	}
+	str, err := bat.UnsafeToString(src)
+	if err != nil {
+		return err
+	}
+	res, err := ParseLTree(strings.Clone(str))
+	if err != nil {
+		return err
+	}
+	*t = res
+	return nil
+}
+
+// Value implements sql/driver.Valuer interface
+func (t LTree) Value() (driver.Value, error) {
+	return string(t), nil
+}
+
+// NullLTree is a nullable LTree
+type NullLTree struct {
+	LTree
+	Valid bool
+}
+
+// Scan implements sql.Scanner interface
+func (t *NullLTree) Scan(src interface{}) error {
+	if src == nil {
+		*t = NullLTree{}
+		return nil
+	}
+	if err := t.LTree.Scan(src); err != nil {
+		return err
+	}
+	t.Valid = true
+	return nil
+}
+
+// Value implements sql/driver.Valuer interface
+func (t NullLTree) Value() (driver.Value, error) {
+	if !t.Valid {
+		return nil, nil
+	}
+	return t.LTree.Value()
+}
+
+// LTrees is a slice of paths for Postgresql `ltree[]` type. LTrees can't hold
+// NULL elements, use NullLTrees for arrays with NULLs.
+type LTrees []LTree
+
+// Scan implements sql.Scanner interface. NULL is scanned as a nil slice, NULL
+// elements are an error.
+func (ls *LTrees) Scan(src interface{}) error {
+	res, err := scanLTreeArray(src, func(t LTree, null bool, i int) (LTree, error) {
+		if null {
+			return "", fmt.Errorf("Can't scan LTrees: NULL element at index %d", i)
+		}
+		return t, nil
+	})
+	if err == nil {
+		*ls = res
+	}
+	return err
+}
+
+// Value implements sql/driver.Valuer interface. Nil slice is encoded according
+// to NilArrayAsNull.
+func (ls LTrees) Value() (driver.Value, error) {
+	if ls == nil && NilArrayAsNull() {
+		return nil, nil
+	}
+	b := []byte{openingArray}
+	for i, t := range ls {
+		if i > 0 {
+			b = append(b, arraySeparator)
+		}
+		b = appendArrayElem(b, string(t), arraySeparator)
+	}
+	return bat.UnsafeByteArrayToStr(append(b, closingArray)), nil
+}
+
+// NullLTrees is a slice of nullable paths for Postgresql `ltree[]` type
+type NullLTrees []NullLTree
+
+// Scan implements sql.Scanner interface. NULL is scanned as a nil slice.
+func (ls *NullLTrees) Scan(src interface{}) error {
+	res, err := scanLTreeArray(src, func(t LTree, null bool, _ int) (NullLTree, error) {
+		return NullLTree{t, !null}, nil
+	})
+	if err == nil {
+		*ls = res
+	}
+	return err
+}
+
+// Value implements sql/driver.Valuer interface. Invalid elements are encoded as
+// NULL, nil slice according to NilArrayAsNull.
+func (ls NullLTrees) Value() (driver.Value, error) {
+	if ls == nil && NilArrayAsNull() {
+		return nil, nil
+	}
+	b := []byte{openingArray}
+	for i, t := range ls {
+		if i > 0 {
+			b = append(b, arraySeparator)
+		}
+		if t.Valid {
+			b = appendArrayElem(b, string(t.LTree), arraySeparator)
+		} else {
+			b = append(b, "NULL"...)
+		}
+	}
+	return bat.UnsafeByteArrayToStr(append(b, closingArray)), nil
+}
+
+// scanLTreeArray parses `ltree[]` src and converts its elements with fn
+func scanLTreeArray[T any](src interface{}, fn func(t LTree, null bool, i int) (T, error)) ([]T, error) {
+	if src == nil {
+		return nil, nil
+	}
+	bs, err := bat.UnsafeToBytes(src)
+	if err != nil {
+		return nil, err
+	}
+	res := []T{}
+	var d ArrayDecoder
+	d.Reset(bs, arraySeparator)
+	for i := 0; d.Next(); i++ {
+		var t LTree
+		if !d.IsNull() {
+			if t, err = ParseLTree(string(d.Elem())); err != nil {
+				return nil, err
+			}
+		}
+		x, err := fn(t, d.IsNull(), i)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, x)
+	}
+	return res, d.Err()
+}
+
+// HasAncestorOf implements the `ls @> t` operator: it returns true if ls
+// contains an ancestor of t.
+func (ls LTrees) HasAncestorOf(t LTree) bool {
+	for _, x := range ls {
+		if x.IsAncestorOf(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasDescendantOf implements the `ls <@ t` operator: it returns true if ls
+// contains a descendant of t.
+func (ls LTrees) HasDescendantOf(t LTree) bool {
+	for _, x := range ls {
+		if x.IsDescendantOf(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// LQuery is a pattern of the Postgresql `lquery` type matching ltree paths
+// with the `~` operator, eg: `Top.*{1,2}.Astronomy|Astrophysics`. Use
+// BuildLQuery to create valid patterns.
+type LQuery string
+
+// LQueryLabel is a label alternative of a lquery level
+type LQueryLabel struct {
+	Label string
+	// Prefix matches labels starting with Label (`*`)
+	Prefix bool
+	// CaseInsensitive matches labels case insensitively (`@`)
+	CaseInsensitive bool
+	// Words matches labels containing all `_` separated words of Label (`%`)
+	Words bool
+}
+
+// LQueryLevel is a level of lquery pattern: one of Labels, or any label if
+// Labels are empty (`*`), repeated from Min to Max times. Max < 0 means no
+// upper limit. Min and Max both 0 (the zero value) mean no repetition
+// quantifier: exactly one label, or any number of labels for `*`. Negated
+// matches any label except Labels (`!`).
+type LQueryLevel struct {
+	Labels  []LQueryLabel
+	Negated bool
+	Min     int
+	Max     int
+}
+
+// LQueryAny returns level matching from min to max labels (`*{min,max}`). max
+// < 0 means no upper limit, as does max 0 when min is 0 (see LQueryLevel).
+func LQueryAny(min, max int) LQueryLevel {
+	return LQueryLevel{Min: min, Max: max}
+}
+
+// LQueryOneOf returns level matching one of the labels (`a|b`)
+func LQueryOneOf(labels ...string) LQueryLevel {
+	l := LQueryLevel{Min: 1, Max: 1, Labels: make([]LQueryLabel, len(labels))}
+	for i, s := range labels {
+		l.Labels[i].Label = s
+	}
+	return l
+}
+
+// LQueryPath returns levels matching exactly the labels of t
+func LQueryPath(t LTree) []LQueryLevel {
+	labels := t.Labels()
+	res := make([]LQueryLevel, len(labels))
+	for i, s := range labels {
+		res[i] = LQueryOneOf(s)
+	}
+	return res
+}
+
+// BuildLQuery creates lquery of the levels. lquery has no escaping, so labels
+// are validated the same way as ltree labels and an error is returned for
+// labels which would change the pattern syntax.
+func BuildLQuery(levels ...LQueryLevel) (LQuery, error) {
+	if len(levels) == 0 {
+		return "", errors.New("Can't build lquery: no levels")
+	}
+	var b []byte
+	for i, l := range levels {
+		if i > 0 {
+			b = append(b, '.')
+		}
+		var err error
+		if b, err = appendLQueryLevel(b, l); err != nil {
+			return "", fmt.Errorf("Can't build lquery: %v at level %d", err, i+1)
+		}
+	}
+	return LQuery(b), nil
+}
+
+func appendLQueryLevel(b []byte, l LQueryLevel) ([]byte, error) {
+	if l.Min < 0 || l.Min > ltreeMaxLevels || l.Max > ltreeMaxLevels || l.Max >= 0 && l.Max < l.Min {
+		return nil, fmt.Errorf("wrong repetition {%d,%d}", l.Min, l.Max)
+	}
+	if len(l.Labels) == 0 {
+		if l.Negated {
+			return nil, errors.New("negated level without labels")
+		}
+		b = append(b, '*')
+		if l.Min == 0 && l.Max <= 0 {
+			return b, nil
+		}
+		return appendLQueryRepetition(b, l.Min, l.Max), nil
+	}
+	if l.Negated {
+		b = append(b, '!')
+	}
+	for i, x := range l.Labels {
+		if err := checkLTreeLabel(x.Label); err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			b = append(b, '|')
+		}
+		b = append(b, x.Label...)
+		if x.CaseInsensitive {
+			b = append(b, '@')
+		}
+		if x.Prefix {
+			b = append(b, '*')
+		}
+		if x.Words {
+			b = append(b, '%')
+		}
+	}
+	if l.Min == 1 && l.Max == 1 || l.Min == 0 && l.Max == 0 {
+		return b, nil
+	}
+	return appendLQueryRepetition(b, l.Min, l.Max), nil
+}
+
+func appendLQueryRepetition(b []byte, min, max int) []byte {
+	b = append(b, '{')
+	if min > 0 || max == min || max < 0 {
+		b = strconv.AppendInt(b, int64(min), 10)
+	}
+	if max != min {
+		b = append(b, ',')
+		if max >= 0 {
+			b = strconv.AppendInt(b, int64(max), 10)
+		}
+	}
+	return append(b, '}')
+}
+
+// String returns q as a string
+func (q LQuery) String() string { return string(q) }
@@ -0,0 +1,184 @@
+package pgt
+
+import (
+	"strings"
+
+	. "github.com/robert-zaremba/checkers"
+	. "gopkg.in/check.v1"
+)
+
+type LTreeSuite struct{}
+
+func (suite *LTreeSuite) TestParse(c *C) {
+	for _, src := range []string{"", "Top", "Top.Science.Astronomy", "a_b.c-d.Zażółć.42"} {
+		t, err := ParseLTree(src)
+		c.Check(err, IsNil, Commentf("%q", src))
+		c.Check(t, Equals, LTree(src))
+	}
+	for _, src := range []string{".", "a.", ".a", "a..b", "a b", "a.b*", "a'b", strings.Repeat("x", 1001)} {
+		_, err := ParseLTree(src)
+		c.Check(err, NotNil, Commentf("%q", src))
+	}
+	_, err := ParseLTree("a.b c")
+	c.Check(err, ErrorMatches, `Can't parse ltree: wrong character ' ' in label "b c" at position 2 at level 2`)
+
+	t, err := NewLTree("Top", "Science")
+	c.Check(err, IsNil)
+	c.Check(t, Equals, LTree("Top.Science"))
+	_, err = NewLTree("Top", "a.b")
+	c.Check(err, NotNil)
+}
+
+func (suite *LTreeSuite) TestOperators(c *C) {
+	t := LTree("Top.Science.Astronomy")
+	c.Check(t.Labels(), DeepEquals, []string{"Top", "Science", "Astronomy"})
+	c.Check(LTree("").Labels(), DeepEquals, []string{})
+	c.Check(t.NLevel(), Equals, 3)
+	c.Check(LTree("").NLevel(), Equals, 0)
+
+	c.Check(LTree("Top.Science").IsAncestorOf(t), IsTrue)
+	c.Check(t.IsAncestorOf(t), IsTrue)
+	c.Check(LTree("").IsAncestorOf(t), IsTrue)
+	c.Check(LTree("Top.Sci").IsAncestorOf(t), IsFalse)
+	c.Check(t.IsAncestorOf("Top.Science"), IsFalse)
+	c.Check(t.IsDescendantOf("Top"), IsTrue)
+	c.Check(t.IsDescendantOf("Top.Science.Astronomy.Stars"), IsFalse)
+
+	c.Check(LTree("Top").Concat("Science"), Equals, LTree("Top.Science"))
+	c.Check(LTree("").Concat("Science"), Equals, LTree("Science"))
+	c.Check(LTree("Top").Concat(""), Equals, LTree("Top"))
+
+	ls := LTrees{"Top.Arts", "Top.Science"}
+	c.Check(ls.HasAncestorOf(t), IsTrue)
+	c.Check(ls.HasAncestorOf("Top"), IsFalse)
+	c.Check(ls.HasDescendantOf("Top"), IsTrue)
+	c.Check(ls.HasDescendantOf(t), IsFalse)
+}
+
+func (suite *LTreeSuite) TestSubpath(c *C) {
+	t := LTree("Top.Child1.Child2.Child3")
+	for _, tc := range []struct {
+		offset, length int
+		expected       LTree
+	}{
+		{0, 2, "Top.Child1"},
+		{1, 10, "Child1.Child2.Child3"},
+		{-2, 1, "Child2"},
+		{1, -1, "Child1.Child2"},
+		{2, 0, ""},
+	} {
+		s, err := t.Subpath(tc.offset, tc.length)
+		c.Check(err, IsNil)
+		c.Check(s, Equals, tc.expected, Commentf("%d, %d", tc.offset, tc.length))
+	}
+	for _, pos := range [][2]int{{4, 1}, {-5, 1}, {2, -3}} {
+		_, err := t.Subpath(pos[0], pos[1])
+		c.Check(err, NotNil, Commentf("%v", pos))
+	}
+	_, err := LTree("").Subpath(0, 0)
+	c.Check(err, NotNil)
+
+	s, err := t.Subltree(1, 3)
+	c.Check(err, IsNil)
+	c.Check(s, Equals, LTree("Child1.Child2"))
+	s, err = t.Subltree(3, 10)
+	c.Check(err, IsNil)
+	c.Check(s, Equals, LTree("Child3"))
+	_, err = t.Subltree(2, 1)
+	c.Check(err, ErrorMatches, `Invalid positions of subltree\("Top.Child1.Child2.Child3", 2, 1\)`)
+}
+
+func (suite *LTreeSuite) TestLCA(c *C) {
+	for _, tc := range []struct {
+		trees    []LTree
+		expected LTree
+	}{
+		{[]LTree{"1.2.3", "1.2.3.4.5.6"}, "1.2"},
+		{[]LTree{"1.2.3", "1.2.3"}, "1.2"},
+		{[]LTree{"1.2.3.4.5.6", "1.2.3.4.7", "1.2.8"}, "1.2"},
+		{[]LTree{"1.2", "3.4"}, ""},
+		{[]LTree{"1"}, ""},
+	} {
+		t, ok := LCA(tc.trees...)
+		c.Check(ok, IsTrue)
+		c.Check(t, Equals, tc.expected, Commentf("%v", tc.trees))
+	}
+	_, ok := LCA()
+	c.Check(ok, IsFalse)
+	_, ok = LCA("1.2", "")
+	c.Check(ok, IsFalse)
+}
+
+func (suite *LTreeSuite) TestSQL(c *C) {
+	var t LTree
+	c.Assert(t.Scan([]byte("Top.Science")), IsNil)
+	c.Check(t, Equals, LTree("Top.Science"))
+	c.Check(mustValue(c, t), Equals, "Top.Science")
+	c.Check(t.Scan("Top..Science"), NotNil)
+	c.Assert(t.Scan(nil), IsNil)
+	c.Check(t, Equals, LTree(""))
+
+	var nt NullLTree
+	c.Assert(nt.Scan("Top"), IsNil)
+	c.Check(nt, Equals, NullLTree{"Top", true})
+	c.Check(mustValue(c, nt), Equals, "Top")
+	c.Assert(nt.Scan(""), IsNil)
+	c.Check(nt, Equals, NullLTree{"", true})
+	c.Check(mustValue(c, nt), Equals, "")
+	c.Assert(nt.Scan(nil), IsNil)
+	c.Check(nt.Valid, IsFalse)
+	c.Check(mustValue(c, nt), IsNil)
+	c.Check(nt.Scan("Top..Science"), NotNil)
+
+	var ls LTrees
+	c.Assert(ls.Scan([]byte(`{Top.Science,"","NULL",Top}`)), IsNil)
+	c.Check(ls, DeepEquals, LTrees{"Top.Science", "", "NULL", "Top"})
+	c.Check(mustValue(c, ls), Equals, `{Top.Science,"","NULL",Top}`)
+	c.Check(ls.Scan(`{"a b"}`), NotNil)
+	c.Check(ls.Scan(`{a,NULL}`), ErrorMatches, "Can't scan LTrees: NULL element at index 1")
+	c.Check(ls, DeepEquals, LTrees{"Top.Science", "", "NULL", "Top"})
+	c.Assert(ls.Scan(nil), IsNil)
+	c.Check(ls, IsNil)
+
+	var nls NullLTrees
+	c.Assert(nls.Scan([]byte(`{Top.Science,"",NULL,"NULL"}`)), IsNil)
+	c.Check(nls, DeepEquals, NullLTrees{{"Top.Science", true}, {"", true}, {}, {"NULL", true}})
+	c.Check(mustValue(c, nls), Equals, `{Top.Science,"",NULL,"NULL"}`)
+	c.Check(nls.Scan(`{a,"b c"}`), NotNil)
+	c.Assert(nls.Scan(nil), IsNil)
+	c.Check(nls, IsNil)
+}
+
+func (suite *LTreeSuite) TestBuildLQuery(c *C) {
+	q, err := BuildLQuery(append(LQueryPath("Top.Science"),
+		LQueryAny(1, 2),
+		LQueryLevel{Labels: []LQueryLabel{{Label: "astro", Prefix: true, CaseInsensitive: true}, {Label: "stars_big", Words: true}}, Min: 1, Max: 1},
+		LQueryLevel{Labels: []LQueryLabel{{Label: "Private"}}, Negated: true, Min: 0, Max: -1},
+		LQueryAny(0, -1),
+		LQueryAny(2, 2),
+		LQueryAny(0, 3),
+		LQueryAny(2, -1),
+		LQueryOneOf("a", "b"),
+		LQueryLevel{Labels: []LQueryLabel{{Label: "c"}}},
+		LQueryLevel{},
+	)...)
+	c.Assert(err, IsNil)
+	c.Check(q, Equals, LQuery(`Top.Science.*{1,2}.astro@*|stars_big%.!Private{0,}.*.*{2}.*{,3}.*{2,}.a|b.c.*`))
+	c.Check(q.String(), Equals, string(q))
+
+	for _, levels := range [][]LQueryLevel{
+		nil,
+		{LQueryOneOf("a|b")},
+		{LQueryOneOf("a.b")},
+		{LQueryOneOf("")},
+		{LQueryAny(2, 1)},
+		{LQueryAny(-1, 1)},
+		{LQueryAny(0, 1<<16)},
+		{{Negated: true, Min: 1, Max: 1}},
+	} {
+		_, err = BuildLQuery(levels...)
+		c.Check(err, NotNil, Commentf("%v", levels))
+	}
+	_, err = BuildLQuery(LQueryOneOf("Top"), LQueryOneOf("x*"))
+	c.Check(err, ErrorMatches, `Can't build lquery: wrong character '\*' in label "x\*" at position 2 at level 2`)
+}
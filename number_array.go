@@ -2,8 +2,8 @@ package pgt
 
 import (
 	"database/sql/driver"
-	"fmt"
-	"strings"
+	"math"
+	"strconv"
 
 	bat "github.com/robert-zaremba/go-bat"
 )
@@ -11,44 +11,157 @@ import (
 // Ints is a slice of long integers for valuer interface
 type Ints []int64
 
-// Scan implements scan methods for scanner
+// Scan implements scan methods for scanner. NULL is scanned as a nil slice. A new
+// slice is always allocated, use IntsBuffer or DecodeInt64Array to reuse memory.
 func (ls *Ints) Scan(src interface{}) error {
+	if src == nil {
+		*ls = nil
+		return nil
+	}
 	bs, err := bat.UnsafeToBytes(src)
 	if err != nil {
 		return err
 	}
-	*ls, err = ParseInt64Array(bs)
-	return err
+	res, err := ParseInt64Array(bs)
+	if err != nil {
+		return err
+	}
+	*ls = res
+	return nil
 }
 
-// Value is the valuer for integer slice
+// Value is the valuer for integer slice. Nil slice is encoded according to
+// NilArrayAsNull.
 func (ls Ints) Value() (driver.Value, error) {
-	res := make([]string, len(ls))
+	if ls == nil && NilArrayAsNull() {
+		return nil, nil
+	}
+	b := []byte{openingArray}
 	for i, v := range ls {
-		res[i] = fmt.Sprintf("%d", v)
+		if i > 0 {
+			b = append(b, arraySeparator)
+		}
+		b = strconv.AppendInt(b, v, 10)
 	}
-	return fmt.Sprintf("{%s}", strings.Join(res, ",")), nil
+	return bat.UnsafeByteArrayToStr(append(b, closingArray)), nil
 }
 
 // Float64s is a slice of floats for valuer interface
 type Float64s []float64
 
-// Scan implements scan methods for scanner
+// Scan implements scan methods for scanner. NULL is scanned as a nil slice. A new
+// slice is always allocated, use Float64sBuffer or DecodeFloatArray to reuse
+// memory.
 func (f *Float64s) Scan(src interface{}) error {
+	if src == nil {
+		*f = nil
+		return nil
+	}
 	bs, err := bat.UnsafeToBytes(src)
 	if err != nil {
 		return err
 	}
-	*f, err = ParseFloatArray(bs)
-	return err
+	res, err := ParseFloatArray(bs)
+	if err != nil {
+		return err
+	}
+	*f = res
+	return nil
 }
 
-// Value is the valuer for float slice
+// Value is the valuer for float slice. Elements are encoded with the shortest
+// representation which round trips, the same as the Postgresql `float8[]`
+// output, including `NaN`, `Infinity` and `-Infinity`. Nil slice is encoded
+// according to NilArrayAsNull.
 func (f Float64s) Value() (driver.Value, error) {
-	ff := []float64(f)
-	res := make([]string, len(ff))
-	for i, v := range ff {
-		res[i] = fmt.Sprintf("%.2f", v)
+	if f == nil && NilArrayAsNull() {
+		return nil, nil
+	}
+	b := []byte{openingArray}
+	for i, v := range f {
+		if i > 0 {
+			b = append(b, arraySeparator)
+		}
+		b = appendFloat(b, v)
+	}
+	return bat.UnsafeByteArrayToStr(append(b, closingArray)), nil
+}
+
+func appendFloat(b []byte, v float64) []byte {
+	switch {
+	case math.IsNaN(v):
+		return append(b, "NaN"...)
+	case math.IsInf(v, 1):
+		return append(b, "Infinity"...)
+	case math.IsInf(v, -1):
+		return append(b, "-Infinity"...)
+	}
+	return strconv.AppendFloat(b, v, 'g', -1, 64)
+}
+
+// IntsBuffer is an Ints which reuses its memory on repeated Scan: the scanned
+// elements overwrite the previous ones when the slice capacity is large enough.
+// It's useful to scan many rows into one variable; copy the result (eg: with
+// slices.Clone) to keep it after the next Scan.
+type IntsBuffer []int64
+
+// Scan implements sql.Scanner interface. NULL is scanned as a nil slice. On
+// error the previous elements can be partially overwritten.
+func (ls *IntsBuffer) Scan(src interface{}) error {
+	if src == nil {
+		*ls = nil
+		return nil
+	}
+	bs, err := bat.UnsafeToBytes(src)
+	if err != nil {
+		return err
+	}
+	res, err := DecodeInt64Array(reuseSlice(*ls, arrayCapacity(bs)), bs)
+	if err != nil {
+		return err
+	}
+	*ls = res
+	return nil
+}
+
+// Value implements sql/driver.Valuer interface, see Ints.Value
+func (ls IntsBuffer) Value() (driver.Value, error) {
+	return Ints(ls).Value()
+}
+
+// Float64sBuffer is a Float64s which reuses its memory on repeated Scan, see
+// IntsBuffer.
+type Float64sBuffer []float64
+
+// Scan implements sql.Scanner interface. NULL is scanned as a nil slice. On
+// error the previous elements can be partially overwritten.
+func (f *Float64sBuffer) Scan(src interface{}) error {
+	if src == nil {
+		*f = nil
+		return nil
+	}
+	bs, err := bat.UnsafeToBytes(src)
+	if err != nil {
+		return err
+	}
+	res, err := DecodeFloatArray(reuseSlice(*f, arrayCapacity(bs)), bs)
+	if err != nil {
+		return err
+	}
+	*f = res
+	return nil
+}
+
+// Value implements sql/driver.Valuer interface, see Float64s.Value
+func (f Float64sBuffer) Value() (driver.Value, error) {
+	return Float64s(f).Value()
+}
+
+// reuseSlice returns s truncated to 0 if it has capacity for n elements, or a
+// new slice with capacity n otherwise
+func reuseSlice[S ~[]E, E any](s S, n int) S {
+	if cap(s) >= n {
+		return s[:0]
 	}
-	return fmt.Sprintf("{%s}", strings.Join(res, ",")), nil
+	return make(S, 0, n)
 }
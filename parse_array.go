@@ -2,8 +2,10 @@ package pgt
 
 import (
 	"bytes"
+	"database/sql/driver"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"unicode/utf8"
 
 	bat "github.com/robert-zaremba/go-bat"
@@ -20,6 +22,56 @@ var (
 	arraySeparatorSlice = []byte{arraySeparator}
 )
 
+var nilArrayAsNull atomic.Bool
+
+// SetNilArrayAsNull defines how the array types Value methods encode nil
+// slices: as SQL NULL when v is true, or as an empty array (`{}`) otherwise
+// (the default). Empty, non nil slices are always encoded as an empty array.
+// It's safe to call it concurrently with encoding.
+func SetNilArrayAsNull(v bool) {
+	nilArrayAsNull.Store(v)
+}
+
+// NilArrayAsNull returns the setting set by SetNilArrayAsNull
+func NilArrayAsNull() bool {
+	return nilArrayAsNull.Load()
+}
+
+// appendArrayElem appends s as an array element, following the Postgresql
+// `array_out` rules: the element is quoted only if it's empty, equals `NULL`
+// (case insensitive), or contains a quote, backslash, brace, the delimiter or
+// white space. Quotes and backslashes are escaped inside quoted elements.
+func appendArrayElem(b []byte, s string, delim byte) []byte {
+	if !arrayElemNeedsQuotes(s, delim) {
+		return append(b, s...)
+	}
+	b = append(b, '"')
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == '"' || c == '\\' {
+			b = append(b, '\\')
+		}
+		b = append(b, s[i])
+	}
+	return append(b, '"')
+}
+
+func arrayElemNeedsQuotes(s string, delim byte) bool {
+	if s == "" || strings.EqualFold(s, "NULL") {
+		return true
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\', openingArray, closingArray, ' ', '\t', '\n', '\r', '\v', '\f':
+			return true
+		default:
+			if c == delim {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // parseArray parses array returned by postgres for []Text column. This implementation
 // is based on observed behaviour of postrgresql regarding syntax of output for []Text columns.
 //
@@ -43,28 +95,38 @@ var (
 func parseArray(source string) ([]string, error) {
 	// return empty array and not nil, because web client cannot handle nil
 	tokens := make([]string, 0)
+	err := splitArray(source, arraySeparator, func(token string, _ bool) {
+		tokens = append(tokens, token)
+	})
+	return tokens, err
+}
+
+// splitArray calls fn for each token of one dimensional array, separated by
+// delim. quoted reports if the token was quoted, which distinguishes the `NULL`
+// element from the `"NULL"` string.
+func splitArray(source string, delim byte, fn func(token string, quoted bool)) error {
 	source = strings.Trim(source, "{}")
 	for len(source) > 0 {
-		token, remaining, err := parseToken(source)
+		token, remaining, err := parseToken(source, delim)
 		if err != nil {
-			return tokens, err
+			return err
 		}
-		tokens = append(tokens, token)
+		fn(token, source[0] == '"')
 		source = remaining
 	}
-	return tokens, nil
+	return nil
 }
 
-func parseToken(source string) (string, string, error) {
+func parseToken(source string, delim byte) (string, string, error) {
 	rune, _ := utf8.DecodeRuneInString(source)
 	if rune == '"' {
-		return parseQuotedToken(source[1:])
+		return parseQuotedToken(source[1:], delim)
 	}
-	return parseUnquotedToken(source)
+	return parseUnquotedToken(source, delim)
 }
 
-func parseUnquotedToken(source string) (string, string, error) {
-	commaPos := strings.IndexRune(source, ',')
+func parseUnquotedToken(source string, delim byte) (string, string, error) {
+	commaPos := strings.IndexByte(source, delim)
 	if commaPos == -1 {
 		return source, "", nil
 	}
@@ -75,7 +137,7 @@ func parseUnquotedToken(source string) (string, string, error) {
 	return source[:commaPos], source[(commaPos + 1):], nil
 }
 
-func parseQuotedToken(source string) (string, string, error) {
+func parseQuotedToken(source string, delim byte) (string, string, error) {
 	var token []byte
 	var runeTmp [utf8.UTFMax]byte
 
@@ -107,7 +169,7 @@ func parseQuotedToken(source string) (string, string, error) {
 	if len(source) == 1 {
 		source = ""
 	} else {
-		if source[1] != ',' {
+		if source[1] != delim {
 			return "", source, strconv.ErrSyntax
 		}
 		source = source[2:]
@@ -116,6 +178,84 @@ func parseQuotedToken(source string) (string, string, error) {
 	return bat.UnsafeByteArrayToStr(token), source, nil
 }
 
+// ParseArrayDelim parses one dimensional array with elements separated by delim.
+// Postgresql defines the delimiter per element type (`pg_type.typdelim`): it's
+// `,` for all built-in types except `box`, which uses `;`. Unquoted `NULL`
+// elements are returned as invalid Strings.
+func ParseArrayDelim(src string, delim byte) ([]String, error) {
+	res := []String{}
+	err := splitArray(src, delim, func(token string, quoted bool) {
+		if !quoted && strings.EqualFold(token, "NULL") {
+			res = append(res, String{})
+		} else {
+			res = append(res, String{String: token, Valid: true})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// FormatArrayDelim encodes elements as one dimensional array with the delim
+// separator. Invalid Strings are encoded as `NULL`. See ParseArrayDelim.
+func FormatArrayDelim(elems []String, delim byte) string {
+	b := []byte{openingArray}
+	for i, e := range elems {
+		if i > 0 {
+			b = append(b, delim)
+		}
+		if e.Valid {
+			b = appendArrayElem(b, e.String, delim)
+		} else {
+			b = append(b, "NULL"...)
+		}
+	}
+	return bat.UnsafeByteArrayToStr(append(b, closingArray))
+}
+
+// DelimArray is a text representation of one dimensional array with a custom
+// delimiter, eg: `box[]`. Delim must be set before scanning, zero value means
+// `,`. NULL elements are represented by invalid Strings.
+//
+//	boxes := pgt.DelimArray{Delim: ';'}
+//	row.Scan(&boxes) // {(1,1),(0,0);(3,3),(2,2)}
+type DelimArray struct {
+	Elems []String
+	Delim byte
+}
+
+func (a DelimArray) delim() byte {
+	if a.Delim == 0 {
+		return arraySeparator
+	}
+	return a.Delim
+}
+
+// Scan implements sql.Scanner interface. NULL is scanned as nil Elems.
+func (a *DelimArray) Scan(src interface{}) (err error) {
+	if src == nil {
+		a.Elems = nil
+		return nil
+	}
+	str, err := bat.UnsafeToString(src)
+	if err != nil {
+		return err
+	}
+	// unquoted elements reference the source, which can be reused by the driver
+	a.Elems, err = ParseArrayDelim(strings.Clone(str), a.delim())
+	return err
+}
+
+// Value implements sql/driver.Valuer interface. Nil Elems are encoded according
+// to NilArrayAsNull.
+func (a DelimArray) Value() (driver.Value, error) {
+	if a.Elems == nil && NilArrayAsNull() {
+		return nil, nil
+	}
+	return FormatArrayDelim(a.Elems, a.delim()), nil
+}
+
 // SplitSimpleArray splits Postgresql encoded Array into list of bytes of elements.
 // It trims {} characters and split by ','
 func SplitSimpleArray(src []byte) [][]byte {
@@ -153,34 +293,22 @@ func SplitNestedSimpleArray(src []byte) [][]byte {
 	return resp
 }
 
-// ParseFloatArray parses float array column
+// ParseFloatArray parses float array column into a new slice, see
+// DecodeFloatArray
 func ParseFloatArray(src []byte) ([]float64, error) {
-	if bytes.Equal(src, EmptyArray) {
-		return []float64{}, nil
-	}
-	vals := SplitSimpleArray(src)
-	var results = make([]float64, len(vals))
-	var err error
-	for i := range vals {
-		if results[i], err = bat.Atof64(bat.UnsafeByteArrayToStr(vals[i])); err != nil {
-			return nil, err
-		}
+	res, err := DecodeFloatArray(make([]float64, 0, arrayCapacity(src)), src)
+	if err != nil {
+		return nil, err
 	}
-	return results, nil
+	return res, nil
 }
 
-// ParseInt64Array parses int64 array column
+// ParseInt64Array parses int64 array column into a new slice, see
+// DecodeInt64Array
 func ParseInt64Array(src []byte) ([]int64, error) {
-	if bytes.Equal(src, EmptyArray) {
-		return []int64{}, nil
-	}
-	vals := SplitSimpleArray(src)
-	var results = make([]int64, len(vals))
-	var err error
-	for i := range vals {
-		if results[i], err = bat.Atoi64(bat.UnsafeByteArrayToStr(vals[i])); err != nil {
-			return nil, err
-		}
+	res, err := DecodeInt64Array(make([]int64, 0, arrayCapacity(src)), src)
+	if err != nil {
+		return nil, err
 	}
-	return results, nil
+	return res, nil
 }
@@ -37,3 +37,42 @@ func (suite *ArraySuite) TestSplitNestedSimpleArray(c *C) {
 	checkNestedArray("{ {{ 1 },{1 2, 3}}, {}, {{ 001200,1}} }", []string{" {{ 1 },{1 2, 3}}", " {}", " {{ 001200,1}} "}, c,
 		Commentf("3-dimension int array should work"))
 }
+
+func (suite *ArraySuite) TestParseArrayDelim(c *C) {
+	res, err := ParseArrayDelim(`{(1,1),(0,0);"(3,3),(2,2)";NULL;"NULL";"a;b"}`, ';')
+	c.Assert(err, IsNil)
+	c.Check(res, DeepEquals, []String{
+		NewString("(1,1),(0,0)", false),
+		NewString("(3,3),(2,2)", false),
+		{},
+		NewString("NULL", false),
+		NewString("a;b", false),
+	})
+	c.Check(FormatArrayDelim(res, ';'), Equals, `{(1,1),(0,0);(3,3),(2,2);NULL;"NULL";"a;b"}`)
+	c.Check(FormatArrayDelim(res[:2], ','), Equals, `{"(1,1),(0,0)","(3,3),(2,2)"}`)
+
+	res, err = ParseArrayDelim(`{}`, ';')
+	c.Check(err, IsNil)
+	c.Check(res, HasLen, 0)
+	_, err = ParseArrayDelim(`{a;}`, ';')
+	c.Check(err, NotNil)
+	_, err = ParseArrayDelim(`{"a",b}`, ';')
+	c.Check(err, NotNil)
+}
+
+func (suite *ArraySuite) TestDelimArray(c *C) {
+	src := []byte(`{(1,1),(0,0);(3,3),(2,2)}`)
+	a := DelimArray{Delim: ';'}
+	c.Assert(a.Scan(src), IsNil)
+	copy(src, "xxxxxxxxxxx")
+	c.Check(a.Elems, DeepEquals, []String{NewString("(1,1),(0,0)", false), NewString("(3,3),(2,2)", false)})
+	v, err := a.Value()
+	c.Check(err, IsNil)
+	c.Check(v, Equals, `{(1,1),(0,0);(3,3),(2,2)}`)
+
+	a = DelimArray{}
+	c.Assert(a.Scan(`{a,NULL}`), IsNil)
+	c.Check(a.Elems, DeepEquals, []String{NewString("a", false), {}})
+	c.Assert(a.Scan(nil), IsNil)
+	c.Check(a.Elems, IsNil)
+}
@@ -0,0 +1,496 @@
+package pgt
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	bat "github.com/robert-zaremba/go-bat"
+)
+
+// ParseRecord parses Postgresql composite value (record) text representation,
+// eg: `(1,"hello world",,t)`, into fields. Empty unquoted fields are NULL and
+// returned as invalid Strings, while `""` is an empty string. Nested composites
+// and arrays are returned as text, which can be parsed again.
+func ParseRecord(src string) ([]String, error) {
+	if len(src) < 2 || src[0] != '(' || src[len(src)-1] != ')' {
+		return nil, fmt.Errorf("Malformed record literal %q: expecting value in ()", src)
+	}
+	s := src[1 : len(src)-1]
+	var fields []String
+	var buf []byte
+	for i := 0; ; i++ { // i is at the field start
+		buf = buf[:0]
+		quoted, inQuote := false, false
+	field:
+		for ; i < len(s); i++ {
+			c := s[i]
+			switch {
+			case c == '\\':
+				if i+1 == len(s) {
+					return nil, fmt.Errorf("Malformed record literal %q: unexpected end of input", src)
+				}
+				i++
+				buf = append(buf, s[i])
+				quoted = true
+			case inQuote && c == '"':
+				if i+1 < len(s) && s[i+1] == '"' {
+					i++
+					buf = append(buf, '"')
+				} else {
+					inQuote = false
+				}
+			case inQuote:
+				buf = append(buf, c)
+			case c == '"':
+				inQuote, quoted = true, true
+			case c == ',':
+				break field
+			case c == ')':
+				return nil, fmt.Errorf("Malformed record literal %q: junk after right parenthesis", src)
+			default:
+				buf = append(buf, c)
+			}
+		}
+		if inQuote {
+			return nil, fmt.Errorf("Malformed record literal %q: unterminated quoted field", src)
+		}
+		if quoted || len(buf) > 0 {
+			fields = append(fields, String{String: string(buf), Valid: true})
+		} else {
+			fields = append(fields, String{})
+		}
+		if i >= len(s) {
+			return fields, nil
+		}
+	}
+}
+
+// FormatRecord encodes fields as composite value text representation. Invalid
+// Strings are encoded as NULL. See ParseRecord.
+func FormatRecord(fields []String) string {
+	b := []byte{'('}
+	for i, f := range fields {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if f.Valid {
+			b = appendRecordField(b, f.String)
+		}
+	}
+	return bat.UnsafeByteArrayToStr(append(b, ')'))
+}
+
+// appendRecordField appends s as a record field, following the Postgresql
+// `record_out` rules: the field is quoted if it's empty or contains a quote,
+// backslash, parenthesis, comma or white space. Quotes and backslashes are
+// doubled inside quoted fields.
+func appendRecordField(b []byte, s string) []byte {
+	if s != "" && strings.IndexAny(s, "\"\\(), \t\n\r\v\f") < 0 {
+		return append(b, s...)
+	}
+	b = append(b, '"')
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == '"' || c == '\\' {
+			b = append(b, c)
+		}
+		b = append(b, s[i])
+	}
+	return append(b, '"')
+}
+
+// Record maps composite value to the struct T. Struct fields are mapped to the
+// composite attributes by their positions: the first exported field to the first
+// attribute, and every next field to the next attribute. The `pgt` tag can set
+// the 1-based attribute position (`pgt:"3"`), or skip the field (`pgt:"-"`).
+// Attributes without a mapped field are ignored by Scan and encoded as NULL by
+// Value.
+// Embedded structs are not flattened, unlike in encoding/json: an embedded
+// exported struct is a single field mapped to one attribute (a nested
+// composite), and an embedded unexported struct is skipped.
+//
+// Fields can be of any type implementing sql.Scanner and driver.Valuer (eg: pgt
+// types), string, bool, numbers, []byte (`bytea`), time.Time, structs (nested
+// composites), slices (arrays) and pointers to them. NULL can be decoded only to
+// Scanners, pointers and slices.
+type Record[T any] struct {
+	Val   T
+	Valid bool
+}
+
+// NewRecord creates valid Record
+func NewRecord[T any](v T) Record[T] {
+	return Record[T]{v, true}
+}
+
+// Scan implements sql.Scanner interface
+func (r *Record[T]) Scan(src interface{}) error {
+	var zero T
+	r.Val, r.Valid = zero, false
+	if src == nil {
+		return nil
+	}
+	s, err := bat.UnsafeToString(src)
+	if err != nil {
+		return err
+	}
+	if err = UnmarshalRecord(s, &r.Val); err != nil {
+		return err
+	}
+	r.Valid = true
+	return nil
+}
+
+// Value implements sql/driver.Valuer interface
+func (r Record[T]) Value() (driver.Value, error) {
+	if !r.Valid {
+		return nil, nil
+	}
+	return MarshalRecord(r.Val)
+}
+
+// Records is a slice of structs mapped to an array of composites, eg:
+// `array_agg(t)`. See Record for the mapping rules. NULL elements are decoded as
+// zero values.
+type Records[T any] []T
+
+// Scan implements sql.Scanner interface. NULL is scanned as a nil slice.
+func (ls *Records[T]) Scan(src interface{}) error {
+	if src == nil {
+		*ls = nil
+		return nil
+	}
+	bs, err := bat.UnsafeToBytes(src)
+	if err != nil {
+		return err
+	}
+	res := Records[T]{}
+	var d ArrayDecoder
+	d.Reset(bs, arraySeparator)
+	for i := 0; d.Next(); i++ {
+		var v T
+		if !d.IsNull() {
+			if err = UnmarshalRecord(string(d.Elem()), &v); err != nil {
+				return fmt.Errorf("Can't decode record array element %d: %v", i, err)
+			}
+		}
+		res = append(res, v)
+	}
+	if err = d.Err(); err != nil {
+		return err
+	}
+	*ls = res
+	return nil
+}
+
+// Value implements sql/driver.Valuer interface. Nil slice is encoded according
+// to NilArrayAsNull.
+func (ls Records[T]) Value() (driver.Value, error) {
+	if ls == nil && NilArrayAsNull() {
+		return nil, nil
+	}
+	b := []byte{openingArray}
+	for i := range ls {
+		if i > 0 {
+			b = append(b, arraySeparator)
+		}
+		s, err := MarshalRecord(ls[i])
+		if err != nil {
+			return nil, err
+		}
+		b = appendArrayElem(b, s, arraySeparator)
+	}
+	return bat.UnsafeByteArrayToStr(append(b, closingArray)), nil
+}
+
+// UnmarshalRecord decodes composite value text representation into the struct
+// pointed by dest. See Record for the mapping rules.
+func UnmarshalRecord(src string, dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Can't unmarshal record into %T: expecting pointer to struct", dest)
+	}
+	return decodeRecord(v.Elem(), src)
+}
+
+// MarshalRecord encodes struct v (or pointer to struct) as composite value text
+// representation. See Record for the mapping rules.
+func MarshalRecord(v interface{}) (string, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return "", fmt.Errorf("Can't marshal %T as record: expecting struct", v)
+	}
+	return encodeRecord(rv)
+}
+
+type recordField struct {
+	index []int
+	pos   int // 0-based attribute position
+}
+
+var recordFieldsCache sync.Map // reflect.Type -> []recordField
+
+func recordFields(t reflect.Type) ([]recordField, error) {
+	if fs, ok := recordFieldsCache.Load(t); ok {
+		return fs.([]recordField), nil
+	}
+	var fields []recordField
+	used := map[int]bool{}
+	pos := 0
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("pgt")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		if tag != "" {
+			n, err := strconv.Atoi(tag)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("Wrong pgt tag %q of %s.%s: expecting attribute position", tag, t, f.Name)
+			}
+			pos = n - 1
+		}
+		if used[pos] {
+			return nil, fmt.Errorf("Duplicated attribute position %d of %s.%s", pos+1, t, f.Name)
+		}
+		used[pos] = true
+		fields = append(fields, recordField{f.Index, pos})
+		pos++
+	}
+	recordFieldsCache.Store(t, fields)
+	return fields, nil
+}
+
+func decodeRecord(v reflect.Value, src string) error {
+	fields, err := recordFields(v.Type())
+	if err != nil {
+		return err
+	}
+	values, err := ParseRecord(src)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if f.pos >= len(values) {
+			return fmt.Errorf("Can't decode record %q into %s: missing attribute %d", src, v.Type(), f.pos+1)
+		}
+		fv := v.FieldByIndex(f.index)
+		if err = decodeValue(fv, values[f.pos]); err != nil {
+			return fmt.Errorf("Can't decode attribute %d into %s.%s: %v",
+				f.pos+1, v.Type(), v.Type().FieldByIndex(f.index).Name, err)
+		}
+	}
+	return nil
+}
+
+func encodeRecord(v reflect.Value) (string, error) {
+	fields, err := recordFields(v.Type())
+	if err != nil {
+		return "", err
+	}
+	var values []String
+	for _, f := range fields {
+		for len(values) <= f.pos {
+			values = append(values, String{})
+		}
+		if values[f.pos], err = encodeValue(v.FieldByIndex(f.index)); err != nil {
+			return "", fmt.Errorf("Can't encode %s.%s: %v", v.Type(), v.Type().FieldByIndex(f.index).Name, err)
+		}
+	}
+	return FormatRecord(values), nil
+}
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// decodeValue decodes text representation of a value into v
+func decodeValue(v reflect.Value, s String) error {
+	if v.Kind() != reflect.Ptr && v.Addr().Type().Implements(scannerType) {
+		scanner := v.Addr().Interface().(sql.Scanner)
+		if !s.Valid {
+			return scanner.Scan(nil)
+		}
+		return scanner.Scan([]byte(s.String))
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !s.Valid {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		elem := reflect.New(v.Type().Elem())
+		if err := decodeValue(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	case reflect.Slice:
+		if !s.Valid {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+	default:
+		if !s.Valid {
+			return fmt.Errorf("can't decode NULL into %s", v.Type())
+		}
+	}
+
+	str := s.String
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(str)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, err := strconv.ParseInt(str, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(x)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, err := strconv.ParseUint(str, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(x)
+	case reflect.Float32, reflect.Float64:
+		x, err := strconv.ParseFloat(str, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(x)
+	case reflect.Struct:
+		if v.Type() == timeType {
+			t, err := ParseTime(str)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(t.Time))
+			return nil
+		}
+		return decodeRecord(v, str)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if !strings.HasPrefix(str, `\x`) {
+				return errors.New("expecting bytea in the hex format")
+			}
+			b, err := hex.DecodeString(str[2:])
+			if err != nil {
+				return err
+			}
+			v.SetBytes(b)
+			return nil
+		}
+		elems, err := ParseArrayDelim(str, arraySeparator)
+		if err != nil {
+			return err
+		}
+		res := reflect.MakeSlice(v.Type(), len(elems), len(elems))
+		for i, e := range elems {
+			if err = decodeValue(res.Index(i), e); err != nil {
+				return fmt.Errorf("array element %d: %v", i, err)
+			}
+		}
+		v.Set(res)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// encodeValue returns text representation of v
+func encodeValue(v reflect.Value) (String, error) {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return String{}, nil
+	}
+	if v.Type().Implements(valuerType) {
+		dv, err := v.Interface().(driver.Valuer).Value()
+		if err != nil {
+			return String{}, err
+		}
+		return driverValueText(dv)
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		return encodeValue(v.Elem())
+	case reflect.String:
+		return String{String: v.String(), Valid: true}, nil
+	case reflect.Bool:
+		if v.Bool() {
+			return String{String: "t", Valid: true}, nil
+		}
+		return String{String: "f", Valid: true}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return String{String: strconv.FormatInt(v.Int(), 10), Valid: true}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return String{String: strconv.FormatUint(v.Uint(), 10), Valid: true}, nil
+	case reflect.Float32, reflect.Float64:
+		return String{String: string(appendFloat(nil, v.Float())), Valid: true}, nil
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return driverValueText(v.Interface())
+		}
+		s, err := encodeRecord(v)
+		return String{String: s, Valid: err == nil}, err
+	case reflect.Slice:
+		if v.IsNil() {
+			return String{}, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return String{String: `\x` + hex.EncodeToString(v.Bytes()), Valid: true}, nil
+		}
+		elems := make([]String, v.Len())
+		for i := range elems {
+			var err error
+			if elems[i], err = encodeValue(v.Index(i)); err != nil {
+				return String{}, fmt.Errorf("array element %d: %v", i, err)
+			}
+		}
+		return String{String: FormatArrayDelim(elems, arraySeparator), Valid: true}, nil
+	}
+	return String{}, fmt.Errorf("unsupported type %s", v.Type())
+}
+
+// driverValueText returns text representation of driver.Value
+func driverValueText(v driver.Value) (String, error) {
+	var s string
+	switch x := v.(type) {
+	case nil:
+		return String{}, nil
+	case string:
+		s = x
+	case []byte:
+		s = string(x)
+	case int64:
+		s = strconv.FormatInt(x, 10)
+	case float64:
+		s = string(appendFloat(nil, x))
+	case bool:
+		s = "f"
+		if x {
+			s = "t"
+		}
+	case time.Time:
+		s = string(appendTimestamp(nil, x))
+	default:
+		return String{}, fmt.Errorf("unsupported driver value %T", v)
+	}
+	return String{String: s, Valid: true}, nil
+}
@@ -0,0 +1,164 @@
+package pgt
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type RecordSuite struct{}
+
+func (suite *RecordSuite) TestParseRecord(c *C) {
+	fields, err := ParseRecord(`(1,"hello world",,t,"",a""b,"x""y\\z","(2,""a b"")")`)
+	c.Assert(err, IsNil)
+	c.Check(fields, DeepEquals, []String{
+		NewString("1", false),
+		NewString("hello world", false),
+		{},
+		NewString("t", false),
+		NewString("", false),
+		NewString(`ab`, false),
+		NewString(`x"y\z`, false),
+		NewString(`(2,"a b")`, false),
+	})
+	c.Check(FormatRecord(fields), Equals, `(1,"hello world",,t,"",ab,"x""y\\z","(2,""a b"")")`)
+
+	fields, err = ParseRecord(`()`)
+	c.Check(err, IsNil)
+	c.Check(fields, DeepEquals, []String{{}})
+	fields, err = ParseRecord(`(,)`)
+	c.Check(err, IsNil)
+	c.Check(fields, DeepEquals, []String{{}, {}})
+	fields, err = ParseRecord(`(a\,b)`)
+	c.Check(err, IsNil)
+	c.Check(fields, DeepEquals, []String{NewString("a,b", false)})
+
+	for _, src := range []string{``, `(`, `a`, `("a)`, `(a)b)`, `(a\)`} {
+		_, err = ParseRecord(src)
+		c.Check(err, NotNil, Commentf("%q", src))
+	}
+}
+
+type recordPoint struct {
+	X, Y float64
+}
+
+type recordItem struct {
+	ID      int64
+	Name    string
+	Note    String
+	Active  bool
+	Tags    []string
+	At      Time
+	Point   recordPoint
+	Parent  *int32
+	Data    []byte
+	private int
+	Skipped string `pgt:"-"`
+	Last    UUID   `pgt:"11"`
+}
+
+func (suite *RecordSuite) TestMarshalRecord(c *C) {
+	id := RandomUUID()
+	parent := int32(7)
+	item := recordItem{
+		ID:     1,
+		Name:   "hello world",
+		Active: true,
+		Tags:   []string{"a b", "c"},
+		At:     NewTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)),
+		Point:  recordPoint{1.5, -2},
+		Parent: &parent,
+		Data:   []byte{1, 255},
+		Last:   id,
+	}
+	s, err := MarshalRecord(item)
+	c.Assert(err, IsNil)
+	c.Check(s, Equals, `(1,"hello world",,t,"{""a b"",c}","2020-01-02 03:04:05+00","(1.5,-2)",7,"\\x01ff",,`+id.String()+`)`)
+
+	var dest recordItem
+	c.Assert(UnmarshalRecord(s, &dest), IsNil)
+	c.Check(dest, DeepEquals, item)
+
+	s, err = MarshalRecord(&recordItem{Name: ""})
+	c.Assert(err, IsNil)
+	c.Check(s, Equals, `(0,"",,f,,,"(0,0)",,,,)`)
+	dest = recordItem{Parent: &parent, Tags: []string{"x"}}
+	c.Assert(UnmarshalRecord(s, &dest), IsNil)
+	c.Check(dest, DeepEquals, recordItem{})
+
+	c.Check(UnmarshalRecord(`(,a)`, &dest), ErrorMatches, "Can't decode attribute 1 into pgt.recordItem.ID: can't decode NULL into int64")
+	c.Check(UnmarshalRecord(`(1,a)`, &dest), ErrorMatches, ".*missing attribute 3")
+	c.Check(UnmarshalRecord(`(1)`, dest), NotNil)
+	_, err = MarshalRecord(1)
+	c.Check(err, NotNil)
+}
+
+type recordWrongTag struct {
+	A int `pgt:"x"`
+}
+
+type recordDuplicatedPos struct {
+	A int `pgt:"2"`
+	B int `pgt:"2"`
+}
+
+type RecordPoint struct {
+	X, Y float64
+}
+
+type recordEmbedding struct {
+	RecordPoint
+	recordItem
+	Name string
+}
+
+func (suite *RecordSuite) TestEmbeddedStruct(c *C) {
+	v := recordEmbedding{RecordPoint: RecordPoint{1, 2}, Name: "a"}
+	s, err := MarshalRecord(v)
+	c.Assert(err, IsNil)
+	c.Check(s, Equals, `("(1,2)",a)`)
+	var dest recordEmbedding
+	c.Assert(UnmarshalRecord(s, &dest), IsNil)
+	c.Check(dest, DeepEquals, v)
+}
+
+func (suite *RecordSuite) TestTags(c *C) {
+	var a recordWrongTag
+	c.Check(UnmarshalRecord(`(1)`, &a), ErrorMatches, `Wrong pgt tag "x".*`)
+	var b recordDuplicatedPos
+	c.Check(UnmarshalRecord(`(1,2)`, &b), ErrorMatches, `Duplicated attribute position 2.*`)
+}
+
+func (suite *RecordSuite) TestRecordAndRecords(c *C) {
+	var r Record[recordPoint]
+	c.Assert(r.Scan([]byte(`(1,2)`)), IsNil)
+	c.Check(r, DeepEquals, NewRecord(recordPoint{1, 2}))
+	v, err := r.Value()
+	c.Check(err, IsNil)
+	c.Check(v, Equals, "(1,2)")
+	c.Assert(r.Scan(nil), IsNil)
+	c.Check(r, DeepEquals, Record[recordPoint]{})
+	v, err = r.Value()
+	c.Check(err, IsNil)
+	c.Check(v, IsNil)
+
+	var ls Records[recordPoint]
+	c.Assert(ls.Scan([]byte(`{"(1,2)",NULL,"(3,4)"}`)), IsNil)
+	c.Check(ls, DeepEquals, Records[recordPoint]{{1, 2}, {}, {3, 4}})
+	v, err = ls.Value()
+	c.Check(err, IsNil)
+	c.Check(v, Equals, `{"(1,2)","(0,0)","(3,4)"}`)
+	c.Check(ls.Scan(`{"(1,x)"}`), ErrorMatches, "Can't decode record array element 0: .*")
+
+	type named struct {
+		Name string
+		Tags Strings
+	}
+	var ns Records[named]
+	c.Assert(ns.Scan(`{"(\"hello world\",\"{a,\"\"b c\"\"}\")","(x,)"}`), IsNil)
+	c.Check(ns, DeepEquals, Records[named]{{"hello world", Strings{"a", "b c"}}, {"x", nil}})
+	v, err = ns.Value()
+	c.Check(err, IsNil)
+	c.Check(v, Equals, `{"(\"hello world\",\"{a,\"\"b c\"\"}\")","(x,{})"}`)
+}
@@ -81,30 +81,50 @@ func FilterValidStrings(ss ...String) []string {
 // Scan implements sql.Scanner for the String slice type
 // Scanners take the database value (in this case as a byte slice)
 // and sets the value of the type.  Here we cast to a string and
-// do a regexp based parse
+// do a regexp based parse. NULL is scanned as a nil slice. Strings can't hold
+// NULL elements, so an unquoted `NULL` element is an error (the `"NULL"` string
+// is scanned as is).
 func (s *Strings) Scan(src interface{}) error {
+	if src == nil {
+		*s = nil
+		return nil
+	}
 	str, err := bat.UnsafeToString(src)
 	if err != nil {
 		return err
 	}
-	parsed, err := parseArray(str)
+	res, nullAt := Strings{}, -1
+	err = splitArray(str, arraySeparator, func(token string, quoted bool) {
+		if !quoted && token == "NULL" && nullAt < 0 {
+			nullAt = len(res)
+		}
+		res = append(res, token)
+	})
 	if err != nil {
 		return err
 	}
-	*s = Strings(parsed)
+	if nullAt >= 0 {
+		return fmt.Errorf("Can't scan Strings: NULL element at index %d", nullAt)
+	}
+	*s = res
 	return nil
 }
 
-// Value is the valuer for string slice which serializes it via comma
-// Returns error if one of strings contain comma
+// Value is the valuer for string slice. Elements are quoted only when needed,
+// so the result is the same as the Postgresql `text[]` output. Nil slice is
+// encoded according to NilArrayAsNull.
 func (s Strings) Value() (driver.Value, error) {
-	quoted := make([]string, len(s))
+	if s == nil && NilArrayAsNull() {
+		return nil, nil
+	}
+	b := []byte{openingArray}
 	for i := range s {
-		escapedBackslash := strings.Replace(s[i], "\\", "\\\\", -1)
-		escapedQuote := strings.Replace(escapedBackslash, "\"", "\\\"", -1)
-		quoted[i] = fmt.Sprintf("\"%s\"", escapedQuote)
+		if i > 0 {
+			b = append(b, arraySeparator)
+		}
+		b = appendArrayElem(b, s[i], arraySeparator)
 	}
-	return fmt.Sprintf("{%s}", strings.Join(quoted, ",")), nil
+	return bat.UnsafeByteArrayToStr(append(b, closingArray)), nil
 }
 
 // Equals compares if two string slices are equal
@@ -122,9 +142,10 @@ func (s Strings) Equals(s2 Strings) bool {
 
 // Exclude removes existing items from source
 func (s Strings) Exclude(ss ...string) Strings {
+	exclude := stringSet(ss)
 	newS := Strings{}
 	for _, e := range s {
-		if !Strings(ss).Contains(e) {
+		if _, ok := exclude[e]; !ok {
 			newS = append(newS, e)
 		}
 	}
@@ -143,16 +164,29 @@ func (s Strings) Contains(val string) bool {
 
 // ContainsAll checks if s contains all vals
 func (s Strings) ContainsAll(vals []string) bool {
-	for i := range vals {
-		if !s.Contains(vals[i]) {
+	if len(vals) == 0 {
+		return true
+	}
+	set := stringSet(s)
+	for _, v := range vals {
+		if _, ok := set[v]; !ok {
 			return false
 		}
 	}
 	return true
 }
 
+func stringSet(ss []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(ss))
+	for _, s := range ss {
+		set[s] = struct{}{}
+	}
+	return set
+}
+
 // ContainsAllSorted checks if s contains all elements from `s2`.
-// We assume that both slices are sorted.
+// We assume that both slices are sorted byte-wise (`COLLATE "C"`), use
+// ContainsAllSortedCollate for other collations.
 func (s Strings) ContainsAllSorted(s2 Strings) bool {
 	lens, lens2 := len(s), len(s2)
 	if lens2 > lens {
@@ -241,11 +275,11 @@ func (s Strings) Distinct() Strings {
 	if s == nil {
 		return nil
 	}
-	result := Strings{}
-	seen := map[string]bool{}
+	result := make(Strings, 0, len(s))
+	seen := make(map[string]struct{}, len(s))
 	for _, elem := range s {
-		if !seen[elem] {
-			seen[elem] = true
+		if _, ok := seen[elem]; !ok {
+			seen[elem] = struct{}{}
 			result = append(result, elem)
 		}
 	}
@@ -1,7 +1,11 @@
 package pgt
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"math"
 	"math/rand"
+	"reflect"
 
 	"github.com/elgs/gostrgen"
 	. "github.com/robert-zaremba/checkers"
@@ -128,15 +132,62 @@ func (suite *StringSuite) TestArraySameAfterMarshallingAndUmarshaling(c *C) {
 }
 
 func (suite *StringSuite) TestVal(c *C) {
-	testVal(c, `{"mary"}`, "mary")
+	testVal(c, `{mary}`, "mary")
 	testVal(c, "{\"\n\"}", "\n")
 	testVal(c, "{\"\n\"}", `
 `)
-	testVal(c, `{"mary","had"}`, "mary", "had")
+	testVal(c, `{mary,had}`, "mary", "had")
 	testVal(c, `{}`)
 	testVal(c, `{"\""}`, `"`)
 	testVal(c, `{"\\"}`, `\`)
 	testVal(c, "{\"a\nb\"}", "a\nb")
+	testVal(c, `{"",NULLs,"NULL","null","a b","a,b","{a}",ąę}`,
+		"", "NULLs", "NULL", "null", "a b", "a,b", "{a}", "ąę")
+}
+
+func (suite *StringSuite) TestNilArray(c *C) {
+	defer SetNilArrayAsNull(NilArrayAsNull())
+	for _, v := range []driver.Valuer{Strings(nil), Ints(nil), Float64s(nil), Times(nil),
+		Dates(nil), UUIDs(nil), NullUUIDs(nil), FixedUUIDs(nil), NullFixedUUIDs(nil), UUIDSet(nil)} {
+		SetNilArrayAsNull(false)
+		c.Check(mustValue(c, v), Equals, "{}", Commentf("%T", v))
+		SetNilArrayAsNull(true)
+		c.Check(mustValue(c, v), IsNil, Commentf("%T", v))
+	}
+	c.Check(mustValue(c, Strings{}), Equals, "{}")
+
+	for _, s := range []sql.Scanner{&Strings{"a"}, &Ints{1}, &Float64s{1}, &Times{{}},
+		&Dates{{}}, &UUIDs{nil}, &NullUUIDs{nil}, &FixedUUIDs{{}}, &NullFixedUUIDs{{}},
+		&UUIDSet{FixedUUID{}: {}}} {
+		c.Check(s.Scan(nil), IsNil)
+		c.Check(reflect.ValueOf(s).Elem().IsNil(), IsTrue, Commentf("%T", s))
+	}
+}
+
+func (suite *StringSuite) TestStringsScanNull(c *C) {
+	var s Strings
+	c.Assert(s.Scan([]byte(`{"NULL",a}`)), IsNil)
+	c.Check(s, DeepEquals, Strings{"NULL", "a"})
+	c.Check(s.Scan([]byte(`{a,NULL}`)), ErrorMatches, ".*NULL element at index 1")
+	c.Check(s.Scan([]byte(`{NULL}`)), NotNil)
+	c.Check(s, DeepEquals, Strings{"NULL", "a"})
+	c.Assert(s.Scan([]byte(`{NULLs}`)), IsNil)
+	c.Check(s, DeepEquals, Strings{"NULLs"})
+}
+
+func (suite *StringSuite) TestNumberArrayValue(c *C) {
+	c.Check(mustValue(c, Ints{1, -20, 300}), Equals, "{1,-20,300}")
+	c.Check(mustValue(c, Float64s{1, -0.5, 1e-5, 1e20, 0.1, math.NaN(), math.Inf(1), math.Inf(-1)}),
+		Equals, "{1,-0.5,1e-05,1e+20,0.1,NaN,Infinity,-Infinity}")
+	var f Float64s
+	c.Assert(f.Scan([]byte("{1,-0.5,1e-05,0.1}")), IsNil)
+	c.Check(f, DeepEquals, Float64s{1, -0.5, 1e-5, 0.1})
+}
+
+func mustValue(c *C, v driver.Valuer) driver.Value {
+	res, err := v.Value()
+	c.Assert(err, IsNil)
+	return res
 }
 
 func testVal(c *C, expected string, ss ...string) {
@@ -0,0 +1,697 @@
+package pgt
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	bat "github.com/robert-zaremba/go-bat"
+)
+
+// TSWeight is a weight of a tsvector lexeme position: TSWeightA (the highest)
+// to TSWeightD (the default).
+type TSWeight byte
+
+// Weights of lexeme positions
+const (
+	TSWeightA TSWeight = 'A'
+	TSWeightB TSWeight = 'B'
+	TSWeightC TSWeight = 'C'
+	TSWeightD TSWeight = 'D'
+)
+
+// tsMaxPos is the maximum lexeme position, bigger positions are set to it
+const tsMaxPos = 1<<14 - 1
+
+// tsMaxPositions is the maximum number of positions of a lexeme
+const tsMaxPositions = 256
+
+// tsMaxDistance is the maximum distance of the `<N>` operator
+const tsMaxDistance = 1 << 14
+
+// tsMaxLexemeLen is the maximum length of a lexeme in bytes
+const tsMaxLexemeLen = 1<<11 - 1
+
+// tsWeightBit returns bit of the weight used in tsquery weight masks
+func tsWeightBit(w TSWeight) (uint8, bool) {
+	switch w {
+	case 'A', 'a':
+		return 8, true
+	case 'B', 'b':
+		return 4, true
+	case 'C', 'c':
+		return 2, true
+	case 'D', 'd':
+		return 1, true
+	}
+	return 0, false
+}
+
+// TSPosition is a position of a lexeme in a document, with its weight. Zero
+// Weight means TSWeightD.
+type TSPosition struct {
+	Pos    uint16
+	Weight TSWeight
+}
+
+// TSLexeme is a tsvector lexeme with its positions, which may be empty
+type TSLexeme struct {
+	Lexeme    string
+	Positions []TSPosition
+}
+
+// TSVector represents Postgresql `tsvector`: lexemes sorted and without
+// duplicates, each with sorted positions. Use NewTSVector to normalize lexemes.
+// Nil TSVector represents NULL.
+type TSVector []TSLexeme
+
+// NewTSVector creates normalized TSVector the same way as Postgresql does:
+// lexemes are sorted and merged with their positions, positions are sorted and
+// deduplicated (keeping the highest weight), limited to 256 per lexeme and to
+// the 1..16383 range. Empty lexemes and lexemes longer than 2047 bytes are
+// skipped, as `to_tsvector` does.
+func NewTSVector(lexemes ...TSLexeme) TSVector {
+	res := make(TSVector, 0, len(lexemes))
+	for _, l := range lexemes {
+		if l.Lexeme != "" && len(l.Lexeme) <= tsMaxLexemeLen {
+			res = append(res, TSLexeme{l.Lexeme, slices.Clone(l.Positions)})
+		}
+	}
+	slices.SortStableFunc(res, func(a, b TSLexeme) int {
+		return strings.Compare(a.Lexeme, b.Lexeme)
+	})
+	merged := res[:0]
+	for _, l := range res {
+		if n := len(merged); n > 0 && merged[n-1].Lexeme == l.Lexeme {
+			merged[n-1].Positions = append(merged[n-1].Positions, l.Positions...)
+		} else {
+			merged = append(merged, l)
+		}
+	}
+	res = merged
+	for i := range res {
+		res[i].Positions = normalizeTSPositions(res[i].Positions)
+	}
+	return res
+}
+
+func normalizeTSPositions(ps []TSPosition) []TSPosition {
+	if len(ps) == 0 {
+		return nil
+	}
+	res := ps[:0]
+	for _, p := range ps {
+		if p.Pos == 0 {
+			continue
+		}
+		if p.Pos > tsMaxPos {
+			p.Pos = tsMaxPos
+		}
+		switch w := p.Weight &^ 0x20; w { // uppercase
+		case TSWeightA, TSWeightB, TSWeightC, TSWeightD:
+			p.Weight = w
+		default:
+			p.Weight = TSWeightD
+		}
+		res = append(res, p)
+	}
+	slices.SortFunc(res, func(a, b TSPosition) int { return cmpInt(int(a.Pos), int(b.Pos)) })
+	uniq := res[:0]
+	for _, p := range res {
+		n := len(uniq)
+		if n == 0 || uniq[n-1].Pos != p.Pos {
+			uniq = append(uniq, p)
+		} else if p.Weight < uniq[n-1].Weight { // 'A' is the highest weight
+			uniq[n-1].Weight = p.Weight
+		}
+	}
+	res = uniq
+	if len(res) > tsMaxPositions {
+		res = res[:tsMaxPositions]
+	}
+	return res
+}
+
+// ParseTSVector parses the text representation of `tsvector`, eg:
+// `'fat':2,4B cat:3A`. Lexemes can be quoted with `'` (a quote inside is
+// doubled or escaped with `\`) or unquoted (with `\` escaping special
+// characters). Lexemes longer than 2047 bytes are rejected. The result is
+// normalized with NewTSVector.
+func ParseTSVector(src string) (TSVector, error) {
+	var lexemes []TSLexeme
+	i := skipTSSpaces(src, 0)
+	for i < len(src) {
+		var l TSLexeme
+		var err error
+		if l.Lexeme, i, err = readTSLexeme(src, i, false); err != nil {
+			return nil, fmt.Errorf("Can't parse tsvector: %v", err)
+		}
+		if l.Lexeme == "" {
+			return nil, fmt.Errorf("Can't parse tsvector: empty lexeme at position %d", i)
+		}
+		if i < len(src) && src[i] == ':' {
+			if l.Positions, i, err = readTSPositions(src, i+1); err != nil {
+				return nil, fmt.Errorf("Can't parse tsvector: %v", err)
+			}
+		}
+		if i < len(src) && !isTSSpace(src[i]) {
+			return nil, fmt.Errorf("Can't parse tsvector: unexpected %q at position %d", src[i], i+1)
+		}
+		lexemes = append(lexemes, l)
+		i = skipTSSpaces(src, i)
+	}
+	return NewTSVector(lexemes...), nil
+}
+
+func readTSPositions(s string, i int) ([]TSPosition, int, error) {
+	var res []TSPosition
+	for {
+		start := i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		pos, err := strconv.Atoi(s[start:i])
+		if err != nil || pos == 0 {
+			return nil, i, fmt.Errorf("wrong position at position %d", start+1)
+		}
+		p := TSPosition{Pos: uint16(min(pos, tsMaxPos)), Weight: TSWeightD}
+		if i < len(s) {
+			if _, ok := tsWeightBit(TSWeight(s[i])); ok {
+				p.Weight = TSWeight(s[i] &^ 0x20) // uppercase
+				i++
+			}
+		}
+		res = append(res, p)
+		if i == len(s) || s[i] != ',' {
+			return res, i, nil
+		}
+		i++
+	}
+}
+
+// Lexemes returns lexemes of v
+func (v TSVector) Lexemes() []string {
+	res := make([]string, len(v))
+	for i, l := range v {
+		res[i] = l.Lexeme
+	}
+	return res
+}
+
+// Contains checks if v, which must be normalized, contains the lexeme
+func (v TSVector) Contains(lexeme string) bool {
+	_, ok := slices.BinarySearchFunc(v, lexeme, func(l TSLexeme, x string) int {
+		return strings.Compare(l.Lexeme, x)
+	})
+	return ok
+}
+
+// String returns the text representation of v, as returned by Postgresql
+func (v TSVector) String() string {
+	var b []byte
+	for i, l := range v {
+		if i > 0 {
+			b = append(b, ' ')
+		}
+		b = appendTSLexeme(b, l.Lexeme)
+		for j, p := range l.Positions {
+			if j == 0 {
+				b = append(b, ':')
+			} else {
+				b = append(b, ',')
+			}
+			b = strconv.AppendUint(b, uint64(p.Pos), 10)
+			if p.Weight != 0 && p.Weight != TSWeightD {
+				b = append(b, byte(p.Weight))
+			}
+		}
+	}
+	return string(b)
+}
+
+// Scan implements sql.Scanner interface. NULL is scanned as a nil TSVector.
+func (v *TSVector) Scan(src interface{}) error {
+	if src == nil {
+		*v = nil
+		return nil
+	}
+	str, err := bat.UnsafeToString(src)
+	if err != nil {
+		return err
+	}
+	res, err := ParseTSVector(str)
+	if err != nil {
+		return err
+	}
+	*v = res
+	return nil
+}
+
+// Value implements sql/driver.Valuer interface. Nil TSVector is encoded as
+// NULL.
+func (v TSVector) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return v.String(), nil
+}
+
+// appendTSLexeme appends quoted lexeme, the way Postgresql outputs lexemes of
+// tsvector and tsquery
+func appendTSLexeme(b []byte, lexeme string) []byte {
+	b = append(b, '\'')
+	for i := 0; i < len(lexeme); i++ {
+		c := lexeme[i]
+		if c == '\'' || c == '\\' {
+			b = append(b, c)
+		}
+		b = append(b, c)
+	}
+	return append(b, '\'')
+}
+
+func isTSSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
+
+func skipTSSpaces(s string, i int) int {
+	for i < len(s) && isTSSpace(s[i]) {
+		i++
+	}
+	return i
+}
+
+// readTSLexeme reads quoted or unquoted lexeme starting at s[i]. Unquoted
+// lexemes end with a space or `:`, and in tsquery also with an operator.
+func readTSLexeme(s string, i int, query bool) (string, int, error) {
+	start := i
+	l, i, err := readTSLexemeText(s, i, query)
+	if err == nil && len(l) > tsMaxLexemeLen {
+		err = fmt.Errorf("lexeme at position %d is too long (%d bytes, maximum is %d)",
+			start+1, len(l), tsMaxLexemeLen)
+	}
+	return l, i, err
+}
+
+func readTSLexemeText(s string, i int, query bool) (string, int, error) {
+	var b strings.Builder
+	if s[i] == '\'' {
+		for i++; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				if i++; i == len(s) {
+					return "", i, errors.New("unterminated quoted lexeme")
+				}
+			case '\'':
+				if i+1 == len(s) || s[i+1] != '\'' {
+					return b.String(), i + 1, nil
+				}
+				i++
+			}
+			b.WriteByte(s[i])
+		}
+		return "", i, errors.New("unterminated quoted lexeme")
+	}
+	for ; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isTSSpace(c) || c == ':':
+			return b.String(), i, nil
+		case query && strings.IndexByte("!&|()<", c) >= 0:
+			return b.String(), i, nil
+		case c == '\'':
+			return "", i, fmt.Errorf("unexpected quote at position %d", i+1)
+		case c == '\\':
+			if i++; i == len(s) {
+				return "", i, errors.New("unterminated escape")
+			}
+			c = s[i]
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), i, nil
+}
+
+// tsOp is an operator of a tsquery node
+type tsOp uint8
+
+// tsquery node operators, ordered by increasing precedence
+const (
+	tsOpOr tsOp = iota + 1
+	tsOpAnd
+	tsOpPhrase
+	tsOpNot
+	tsOpLexeme
+)
+
+type tsNode struct {
+	op      tsOp
+	lexeme  string
+	prefix  bool
+	weights uint8  // mask of tsWeightBit, 0 matches all weights
+	dist    uint16 // distance of tsOpPhrase
+	left    *tsNode
+	right   *tsNode
+}
+
+// TSQuery represents Postgresql `tsquery`. It's immutable: queries are built
+// with TSTerm, TSPrefix and the operator functions, or parsed with
+// ParseTSQuery. Lexemes are always quoted when the query is encoded, so user
+// input can't break the query syntax:
+//
+//	q := pgt.TSAnd(pgt.TSTerm(word), pgt.TSNot(pgt.TSPrefix("draft", pgt.TSWeightA)))
+//	db.Query(&res, "SELECT ... WHERE doc @@ ?", q)
+//
+// Zero value is the empty query, which is skipped by the operator functions.
+type TSQuery struct {
+	root *tsNode
+}
+
+// TSTerm returns query matching the lexeme with one of the weights (or any
+// weight, if none is specified). Empty lexeme and lexeme longer than 2047 bytes
+// (which Postgresql rejects) give the empty query, so the term is skipped by
+// the operator functions, as in PlainTSQuery. It panics if a weight is not one
+// of TSWeightA..D.
+func TSTerm(lexeme string, weights ...TSWeight) TSQuery {
+	if lexeme == "" || len(lexeme) > tsMaxLexemeLen {
+		return TSQuery{}
+	}
+	n := &tsNode{op: tsOpLexeme, lexeme: lexeme}
+	for _, w := range weights {
+		bit, ok := tsWeightBit(w)
+		if !ok {
+			panic(fmt.Sprintf("Wrong tsquery weight %q", w))
+		}
+		n.weights |= bit
+	}
+	return TSQuery{n}
+}
+
+// TSPrefix returns query matching lexemes starting with prefix (`:*`). See
+// TSTerm.
+func TSPrefix(prefix string, weights ...TSWeight) TSQuery {
+	q := TSTerm(prefix, weights...)
+	if q.root != nil {
+		q.root.prefix = true
+	}
+	return q
+}
+
+// TSNot returns `!q`
+func TSNot(q TSQuery) TSQuery {
+	if q.root == nil {
+		return q
+	}
+	return TSQuery{&tsNode{op: tsOpNot, left: q.root}}
+}
+
+func tsFold(op tsOp, dist uint16, qs []TSQuery) TSQuery {
+	var res *tsNode
+	for _, q := range qs {
+		switch {
+		case q.root == nil:
+		case res == nil:
+			res = q.root
+		default:
+			res = &tsNode{op: op, dist: dist, left: res, right: q.root}
+		}
+	}
+	return TSQuery{res}
+}
+
+// TSAnd returns `q1 & q2 & ...`. Empty queries are skipped.
+func TSAnd(qs ...TSQuery) TSQuery {
+	return tsFold(tsOpAnd, 0, qs)
+}
+
+// TSOr returns `q1 | q2 | ...`. Empty queries are skipped.
+func TSOr(qs ...TSQuery) TSQuery {
+	return tsFold(tsOpOr, 0, qs)
+}
+
+// TSFollowedBy returns `q1 <-> q2 <-> ...`: a phrase of the queries. Empty
+// queries are skipped.
+func TSFollowedBy(qs ...TSQuery) TSQuery {
+	return tsFold(tsOpPhrase, 1, qs)
+}
+
+// TSPhrase returns `a <distance> b`: b must follow a at exactly the distance.
+// Postgresql accepts distances in the 0..16384 range, so distance out of the
+// range is clamped to it.
+func TSPhrase(distance int, a, b TSQuery) TSQuery {
+	distance = max(0, min(distance, tsMaxDistance))
+	return tsFold(tsOpPhrase, uint16(distance), []TSQuery{a, b})
+}
+
+// PlainTSQuery returns query matching all words of text separated by spaces,
+// similarly to `plainto_tsquery` without normalization of the words. Words
+// longer than 2047 bytes are skipped, as in Postgresql.
+func PlainTSQuery(text string) TSQuery {
+	words := strings.Fields(text)
+	qs := make([]TSQuery, 0, len(words))
+	for _, w := range words {
+		qs = append(qs, TSTerm(w))
+	}
+	return TSAnd(qs...)
+}
+
+// IsEmpty returns true for the empty query
+func (q TSQuery) IsEmpty() bool {
+	return q.root == nil
+}
+
+// String returns the text representation of q, the same as returned by
+// Postgresql
+func (q TSQuery) String() string {
+	if q.root == nil {
+		return ""
+	}
+	return string(appendTSNode(nil, q.root))
+}
+
+func appendTSNode(b []byte, n *tsNode) []byte {
+	switch n.op {
+	case tsOpLexeme:
+		b = appendTSLexeme(b, n.lexeme)
+		if n.prefix || n.weights != 0 {
+			b = append(b, ':')
+			if n.prefix {
+				b = append(b, '*')
+			}
+			for _, w := range []TSWeight{TSWeightA, TSWeightB, TSWeightC, TSWeightD} {
+				if bit, _ := tsWeightBit(w); n.weights&bit != 0 {
+					b = append(b, byte(w))
+				}
+			}
+		}
+		return b
+	case tsOpNot:
+		b = append(b, '!')
+		return appendTSOperand(b, n.left, n.left.op < tsOpNot)
+	}
+	b = appendTSOperand(b, n.left, n.left.op < n.op)
+	switch n.op {
+	case tsOpOr:
+		b = append(b, " | "...)
+	case tsOpAnd:
+		b = append(b, " & "...)
+	case tsOpPhrase:
+		if n.dist == 1 {
+			b = append(b, " <-> "...)
+		} else {
+			b = append(b, " <"...)
+			b = strconv.AppendUint(b, uint64(n.dist), 10)
+			b = append(b, "> "...)
+		}
+	}
+	// phrase operators with different distances are not associative
+	return appendTSOperand(b, n.right, n.right.op < n.op || n.right.op == tsOpPhrase && n.op == tsOpPhrase)
+}
+
+func appendTSOperand(b []byte, n *tsNode, parens bool) []byte {
+	if !parens {
+		return appendTSNode(b, n)
+	}
+	b = append(b, "( "...)
+	return append(appendTSNode(b, n), " )"...)
+}
+
+// ParseTSQuery parses the text representation of `tsquery`, eg:
+// `'fat' & ( rat:AB | cat:* ) & !dog <-> food`. Operators, in decreasing
+// precedence, are: `!`, `<->` (and `<N>`), `&` and `|`. Empty lexemes are
+// skipped and blank src is parsed as the empty query, as in Postgresql.
+func ParseTSQuery(src string) (TSQuery, error) {
+	p := tsQueryParser{src: src}
+	if p.skipSpaces() == len(src) {
+		return TSQuery{}, nil
+	}
+	q, err := p.parseOr()
+	if err == nil && p.skipSpaces() < len(src) {
+		err = p.errorf("unexpected %q", src[p.pos])
+	}
+	if err != nil {
+		return TSQuery{}, err
+	}
+	return q, nil
+}
+
+type tsQueryParser struct {
+	src string
+	pos int
+}
+
+func (p *tsQueryParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("Can't parse tsquery: "+format+" at position %d", append(args, p.pos+1)...)
+}
+
+func (p *tsQueryParser) skipSpaces() int {
+	p.pos = skipTSSpaces(p.src, p.pos)
+	return p.pos
+}
+
+// next reports if the next token is c, and skips it
+func (p *tsQueryParser) next(c byte) bool {
+	if p.skipSpaces() < len(p.src) && p.src[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *tsQueryParser) parseOr() (TSQuery, error) {
+	q, err := p.parseAnd()
+	for err == nil && p.next('|') {
+		var r TSQuery
+		r, err = p.parseAnd()
+		q = TSOr(q, r)
+	}
+	return q, err
+}
+
+func (p *tsQueryParser) parseAnd() (TSQuery, error) {
+	q, err := p.parsePhrase()
+	for err == nil && p.next('&') {
+		var r TSQuery
+		r, err = p.parsePhrase()
+		q = TSAnd(q, r)
+	}
+	return q, err
+}
+
+func (p *tsQueryParser) parsePhrase() (TSQuery, error) {
+	q, err := p.parseNot()
+	for err == nil && p.next('<') {
+		var dist int
+		if dist, err = p.parseDistance(); err != nil {
+			break
+		}
+		var r TSQuery
+		r, err = p.parseNot()
+		q = tsFold(tsOpPhrase, uint16(dist), []TSQuery{q, r})
+	}
+	return q, err
+}
+
+// parseDistance parses distance of the phrase operator after `<`
+func (p *tsQueryParser) parseDistance() (int, error) {
+	s := p.src
+	if strings.HasPrefix(s[p.pos:], "->") {
+		p.pos += 2
+		return 1, nil
+	}
+	start := p.pos
+	for p.pos < len(s) && s[p.pos] >= '0' && s[p.pos] <= '9' {
+		p.pos++
+	}
+	dist, err := strconv.Atoi(s[start:p.pos])
+	if err != nil || p.pos == len(s) || s[p.pos] != '>' {
+		p.pos = start
+		return 0, p.errorf("wrong phrase operator")
+	}
+	if dist > tsMaxDistance {
+		p.pos = start
+		return 0, p.errorf("distance in phrase operator bigger than %d", tsMaxDistance)
+	}
+	p.pos++
+	return dist, nil
+}
+
+func (p *tsQueryParser) parseNot() (TSQuery, error) {
+	if p.next('!') {
+		q, err := p.parseNot()
+		return TSNot(q), err
+	}
+	if p.next('(') {
+		q, err := p.parseOr()
+		if err == nil && !p.next(')') {
+			err = p.errorf("expecting ')'")
+		}
+		return q, err
+	}
+	return p.parseOperand()
+}
+
+func (p *tsQueryParser) parseOperand() (TSQuery, error) {
+	if p.skipSpaces() == len(p.src) {
+		return TSQuery{}, p.errorf("unexpected end of query")
+	}
+	if strings.IndexByte("&|)<:", p.src[p.pos]) >= 0 {
+		return TSQuery{}, p.errorf("unexpected %q", p.src[p.pos])
+	}
+	lexeme, i, err := readTSLexeme(p.src, p.pos, true)
+	if err != nil {
+		return TSQuery{}, fmt.Errorf("Can't parse tsquery: %v", err)
+	}
+	p.pos = i
+	q := TSTerm(lexeme)
+	if p.pos < len(p.src) && p.src[p.pos] == ':' {
+		var prefix bool
+		var weights uint8
+		for p.pos++; p.pos < len(p.src); p.pos++ {
+			c := p.src[p.pos]
+			if c == '*' {
+				prefix = true
+			} else if bit, ok := tsWeightBit(TSWeight(c)); ok {
+				weights |= bit
+			} else {
+				break
+			}
+		}
+		if q.root != nil {
+			q.root.prefix, q.root.weights = prefix, weights
+		}
+	}
+	return q, nil
+}
+
+// Scan implements sql.Scanner interface. NULL is scanned as the empty query.
+// Postgresql empty query (an empty string) is scanned as the empty query as
+// well, so it is written back as NULL.
+func (q *TSQuery) Scan(src interface{}) error {
+	if src == nil {
+		*q = TSQuery{}
+		return nil
+	}
+	str, err := bat.UnsafeToString(src)
+	if err != nil {
+		return err
+	}
+	res, err := ParseTSQuery(str)
+	if err != nil {
+		return err
+	}
+	*q = res
+	return nil
+}
+
+// Value implements sql/driver.Valuer interface. The empty query is encoded as
+// NULL, the same way as nil TSVector, so NULL round trips.
+func (q TSQuery) Value() (driver.Value, error) {
+	if q.root == nil {
+		return nil, nil
+	}
+	return q.String(), nil
+}
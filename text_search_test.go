@@ -0,0 +1,125 @@
+package pgt
+
+import (
+	"strings"
+
+	. "github.com/robert-zaremba/checkers"
+	. "gopkg.in/check.v1"
+)
+
+type TextSearchSuite struct{}
+
+func (suite *TextSearchSuite) TestParseTSVector(c *C) {
+	v, err := ParseTSVector(` 'fat':2,4B cat:3a 'a''b' fat:1,4A 'x\\y' c\:d:20000 `)
+	c.Assert(err, IsNil)
+	c.Check(v, DeepEquals, TSVector{
+		{"a'b", nil},
+		{"c:d", []TSPosition{{tsMaxPos, TSWeightD}}},
+		{"cat", []TSPosition{{3, TSWeightA}}},
+		{"fat", []TSPosition{{1, TSWeightD}, {2, TSWeightD}, {4, TSWeightA}}},
+		{`x\y`, nil},
+	})
+	c.Check(v.String(), Equals, `'a''b' 'c:d':16383 'cat':3A 'fat':1,2,4A 'x\\y'`)
+	c.Check(v.Lexemes(), DeepEquals, []string{"a'b", "c:d", "cat", "fat", `x\y`})
+	c.Check(v.Contains("cat"), IsTrue)
+	c.Check(v.Contains("ca"), IsFalse)
+
+	v2, err := ParseTSVector(v.String())
+	c.Check(err, IsNil)
+	c.Check(v2, DeepEquals, v)
+
+	for _, src := range []string{`a:0`, `'abc`, `a:x`, `a:1,`, `a'b`, `''`, `a:1Ab`, `a\`} {
+		_, err = ParseTSVector(src)
+		c.Check(err, NotNil, Commentf("%q", src))
+	}
+
+	long := strings.Repeat("x", 2048)
+	_, err = ParseTSVector("a " + long + ":1")
+	c.Check(err, ErrorMatches, "Can't parse tsvector: lexeme at position 3 is too long.*")
+	v, err = ParseTSVector(long[1:])
+	c.Assert(err, IsNil)
+	c.Check(v.Lexemes(), DeepEquals, []string{long[1:]})
+	c.Check(NewTSVector(TSLexeme{Lexeme: long}, TSLexeme{Lexeme: "a"}).Lexemes(), DeepEquals, []string{"a"})
+}
+
+func (suite *TextSearchSuite) TestTSVectorSQL(c *C) {
+	var v TSVector
+	c.Assert(v.Scan([]byte(`b:2 a`)), IsNil)
+	c.Check(v, DeepEquals, NewTSVector(TSLexeme{"a", nil}, TSLexeme{"b", []TSPosition{{Pos: 2}}}))
+	c.Check(mustValue(c, v), Equals, `'a' 'b':2`)
+	c.Assert(v.Scan(""), IsNil)
+	c.Check(v, DeepEquals, TSVector{})
+	c.Check(mustValue(c, v), Equals, "")
+	c.Assert(v.Scan(nil), IsNil)
+	c.Check(v, IsNil)
+	c.Check(mustValue(c, v), IsNil)
+	c.Check(v.Scan("a:b"), NotNil)
+}
+
+func (suite *TextSearchSuite) TestParseTSQuery(c *C) {
+	for _, tc := range []struct{ src, expected string }{
+		{`fat & ( rat:AB | cat:* ) & !dog <-> food`, `'fat' & ( 'rat':AB | 'cat':* ) & !'dog' <-> 'food'`},
+		{`a | b & c`, `'a' | 'b' & 'c'`},
+		{`(a | b) & c`, `( 'a' | 'b' ) & 'c'`},
+		{`a & (b & c)`, `'a' & 'b' & 'c'`},
+		{`a <2> (b <-> c)`, `'a' <2> ( 'b' <-> 'c' )`},
+		{`(a <0> b) <-> c`, `'a' <0> 'b' <-> 'c'`},
+		{`!(a | b)`, `!( 'a' | 'b' )`},
+		{`!!a`, `!!'a'`},
+		{`a:B*c & 'it''s':*`, `'a':*BC & 'it''s':*`},
+		{`'' & a | ''`, `'a'`},
+		{`a\&b`, `'a&b'`},
+		{`''`, ``},
+		{` `, ``},
+	} {
+		q, err := ParseTSQuery(tc.src)
+		c.Assert(err, IsNil, Commentf("%q", tc.src))
+		c.Check(q.String(), Equals, tc.expected, Commentf("%q", tc.src))
+		q2, err := ParseTSQuery(q.String())
+		c.Check(err, IsNil)
+		c.Check(q2.String(), Equals, tc.expected)
+	}
+
+	for _, src := range []string{`a &`, `& a`, `(a`, `a)`, `a b`, `a <x> b`, `a <16385> b`, `'a`, `()`, `!`} {
+		_, err := ParseTSQuery(src)
+		c.Check(err, NotNil, Commentf("%q", src))
+	}
+}
+
+func (suite *TextSearchSuite) TestTSQueryBuilder(c *C) {
+	input := `it's a \ trap & !(`
+	q := TSAnd(TSTerm(input), TSQuery{}, TSNot(TSOr(TSPrefix("draft", TSWeightA, 'c'), TSTerm("old"))))
+	c.Check(q.String(), Equals, `'it''s a \\ trap & !(' & !( 'draft':*AC | 'old' )`)
+	q2, err := ParseTSQuery(q.String())
+	c.Check(err, IsNil)
+	c.Check(q2, DeepEquals, q)
+
+	c.Check(TSFollowedBy(TSTerm("a"), TSTerm("b"), TSTerm("c")).String(), Equals, `'a' <-> 'b' <-> 'c'`)
+	c.Check(TSPhrase(3, TSTerm("a"), TSAnd(TSTerm("b"), TSTerm("c"))).String(), Equals, `'a' <3> ( 'b' & 'c' )`)
+	c.Check(TSPhrase(0, TSTerm("a"), TSQuery{}).String(), Equals, `'a'`)
+	c.Check(PlainTSQuery(" fat  'rats' ").String(), Equals, `'fat' & '''rats'''`)
+	c.Check(PlainTSQuery(" ").IsEmpty(), IsTrue)
+	c.Check(TSNot(TSTerm("")).IsEmpty(), IsTrue)
+	c.Check(TSPhrase(-1, TSTerm("a"), TSTerm("b")).String(), Equals, `'a' <0> 'b'`)
+	c.Check(TSPhrase(1<<20, TSTerm("a"), TSTerm("b")).String(), Equals, `'a' <16384> 'b'`)
+	c.Check(func() { TSTerm("a", 'E') }, PanicMatches, "Wrong tsquery weight 'E'")
+
+	// lexemes are limited to 2047 bytes
+	long := strings.Repeat("x", 2048)
+	c.Check(TSTerm(long[1:]).String(), Equals, "'"+long[1:]+"'")
+	c.Check(TSTerm(long).IsEmpty(), IsTrue)
+	c.Check(TSAnd(TSTerm("a"), TSPrefix(long, TSWeightA)).String(), Equals, `'a'`)
+	c.Check(PlainTSQuery("a "+long).String(), Equals, `'a'`)
+	_, err = ParseTSQuery("a & '" + long + "'")
+	c.Check(err, ErrorMatches, "Can't parse tsquery: lexeme at position 5 is too long \\(2048 bytes, maximum is 2047\\)")
+
+	var q3 TSQuery
+	c.Assert(q3.Scan([]byte("a & b")), IsNil)
+	c.Check(mustValue(c, q3), Equals, `'a' & 'b'`)
+	c.Assert(q3.Scan(nil), IsNil)
+	c.Check(q3.IsEmpty(), IsTrue)
+	c.Check(mustValue(c, q3), IsNil)
+	c.Assert(q3.Scan(""), IsNil)
+	c.Check(mustValue(c, q3), IsNil)
+	c.Check(q3.Scan("a &"), ErrorMatches, "Can't parse tsquery: unexpected end of query at position 4")
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql/driver"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -23,6 +24,10 @@ import (
 // (best we can do is use time.Time.IsZero()).
 // To overcome this issue, agtime.Time has `Valid` field which enables
 // us to handle nulls property. Mainly replicates pq.NullTime behavior
+//
+// Postgresql `infinity` and `-infinity` timestamps can't be represented by
+// Time: Scan returns an error for them, so they are never mistaken for NULL.
+// Use InfTime for columns which can hold infinity.
 type Time struct {
 	time.Time
 	Valid bool
@@ -42,11 +47,26 @@ func NewTime(t time.Time) Time {
 	return Time{t, true}
 }
 
-// Scan implements Scanner interface
+// Scan implements Scanner interface. It accepts time.Time and Postgresql
+// timestamp text representation (see ParseTime).
 func (t *Time) Scan(value interface{}) error {
-	t.Time, t.Valid = value.(time.Time)
-	t.Time = t.Time.UTC()
-	return nil
+	switch v := value.(type) {
+	case nil:
+		*t = Time{}
+		return nil
+	case time.Time:
+		*t = NewTime(v)
+		return nil
+	case []byte, string:
+		s, _ := bat.UnsafeToString(value)
+		parsed, err := ParseTime(s)
+		if err != nil {
+			return err
+		}
+		*t = parsed
+		return nil
+	}
+	return fmt.Errorf("Scan source was not a timestamp, but %T", value)
 }
 
 // Value implements Valuer interface
@@ -57,60 +77,100 @@ func (t Time) Value() (driver.Value, error) {
 	return t.Time.UTC(), nil
 }
 
-// MarshalJSON implements Marshaller interface
+// MarshalJSON implements Marshaller interface. The encoding is defined by
+// SetTimeJSONFormat (Unix seconds by default).
 func (t Time) MarshalJSON() ([]byte, error) {
-	if !t.Valid {
-		return nullbytes, nil
-	}
-	return []byte(bat.I64toa(t.Unix())), nil
+	return CurrentTimeJSONFormat().marshalJSON(t), nil
 }
 
-// UnmarshalJSON implements Unmarshaller interface
+// UnmarshalJSON implements Unmarshaller interface. It accepts all encodings
+// produced by TimeFormat values (see parseJSONTime for details). Unix
+// timestamps are decoded in the unit set by SetTimeJSONFormat, if it's milli-,
+// micro- or nanoseconds, otherwise the unit is detected from the magnitude of
+// the number (0 and negative numbers are decoded as NULL). Use TimeUnixMilli,
+// TimeUnixMicro and TimeUnixNano to decode timestamps in a fixed unit.
 func (t *Time) UnmarshalJSON(data []byte) (err error) {
 	if bytes.Equal(data, nullbytes) {
-		t.Valid = false
-		return nil
-	}
-	asString := strings.Trim(string(data), "\"")
-	// used when aggregated columns are returned from db
-	parsed, err := time.Parse("2006-01-02T15:04:05", asString)
-	if err == nil {
-		*t = Time{parsed, true}
+		*t = Time{}
 		return nil
 	}
-	unixTime, err := bat.Atoi64(asString)
-	if err != nil {
-		return err
-	}
-	// This is new for 0 or zero time
-	if unixTime <= 0 {
-		t.Valid = false
-	} else {
-		*t = Time{time.Unix(unixTime, 0).UTC(), true}
-	}
-	return nil
+	*t, err = parseJSONTime(strings.Trim(string(data), "\""), untypedUnixUnit())
+	return err
 }
 
 // MarshalBinary implements binary encoding for time
 // This pair of methods are used if agtime.Time is msgpacked.
 //
+// The encoding starts with a tag byte (see binaryTag) holding the encoding
+// version and the value state. NULL values are encoded in the tag only. Valid
+// values are followed by the msgpack timestamp extension
+// payload (4, 8 or 12 bytes), so the whole encoding fits msgpack ext types
+// (fixext 1 or ext 8), and the timestamp can be decoded by msgpack libraries
+// after the tag is removed.
 func (t Time) MarshalBinary() ([]byte, error) {
-	bs := make([]byte, 16)
-	binary.PutVarint(bs, t.Unix())
-	binary.PutVarint(bs[8:], int64(t.Nanosecond()))
+	if !t.Valid {
+		return []byte{binaryTag(binaryNull)}, nil
+	}
+	sec, nsec := t.Time.Unix(), uint64(t.Nanosecond())
+	if sec >= 0 && sec < 1<<34 {
+		if nsec == 0 && sec < 1<<32 {
+			bs := make([]byte, 5)
+			bs[0] = binaryTag(binaryValid)
+			binary.BigEndian.PutUint32(bs[1:], uint32(sec))
+			return bs, nil
+		}
+		bs := make([]byte, 9)
+		bs[0] = binaryTag(binaryValid)
+		binary.BigEndian.PutUint64(bs[1:], nsec<<34|uint64(sec))
+		return bs, nil
+	}
+	bs := make([]byte, 13)
+	bs[0] = binaryTag(binaryValid)
+	binary.BigEndian.PutUint32(bs[1:], uint32(nsec))
+	binary.BigEndian.PutUint64(bs[5:], uint64(sec))
 	return bs, nil
 }
 
-// UnmarshalBinary implements binary decoding for time
+// UnmarshalBinary implements binary decoding for time. Beside the MarshalBinary
+// format it accepts the legacy 16 bytes encoding (two varints).
 func (t *Time) UnmarshalBinary(data []byte) (err error) {
-	var unix, nano int64
-	if unix, err = getBytes(data); err != nil {
-		return
+	var sec, nsec int64
+	if len(data) == 16 {
+		if sec, err = getBytes(data); err != nil {
+			return
+		}
+		if nsec, err = getBytes(data[8:]); err != nil {
+			return
+		}
+	} else {
+		state, payload, err := readBinaryTag(data, "Time")
+		if err != nil {
+			return err
+		}
+		switch state {
+		case binaryNull:
+			*t = Time{}
+			return nil
+		case binaryPosInfinity, binaryNegInfinity:
+			return errors.New("Can't unmarshal infinite timestamp into Time, use InfTime")
+		}
+		switch len(payload) {
+		case 4:
+			sec = int64(binary.BigEndian.Uint32(payload))
+		case 8:
+			x := binary.BigEndian.Uint64(payload)
+			sec, nsec = int64(x&(1<<34-1)), int64(x>>34)
+		case 12:
+			nsec = int64(binary.BigEndian.Uint32(payload))
+			sec = int64(binary.BigEndian.Uint64(payload[4:]))
+		default:
+			return fmt.Errorf("Can't unmarshal Time: wrong payload length %d", len(payload))
+		}
 	}
-	if nano, err = getBytes(data[8:]); err != nil {
-		return
+	if nsec < 0 || nsec >= int64(time.Second) {
+		return errors.New("Can't unmarshal Time: nanoseconds out of range")
 	}
-	*t = Time{time.Unix(unix, nano).UTC(), true}
+	*t = Time{time.Unix(sec, nsec).UTC(), true}
 	return nil
 }
 
@@ -0,0 +1,86 @@
+package pgt
+
+import (
+	"database/sql/driver"
+	"time"
+
+	bat "github.com/robert-zaremba/go-bat"
+)
+
+// Times is a slice of timestamps for Postgresql `timestamp[]` and `timestamptz[]`
+// types. NULL elements are represented by invalid InfTime values.
+type Times []InfTime
+
+// Scan implements sql.Scanner interface. Elements can be in any of the formats
+// accepted by ParseInfTime.
+func (ts *Times) Scan(src interface{}) error {
+	if src == nil {
+		*ts = nil
+		return nil
+	}
+	str, err := bat.UnsafeToString(src)
+	if err != nil {
+		return err
+	}
+	elems, err := parseArray(str)
+	if err != nil {
+		return err
+	}
+	res := make(Times, len(elems))
+	for i, e := range elems {
+		if e == "NULL" {
+			continue
+		}
+		if res[i], err = ParseInfTime(e); err != nil {
+			return err
+		}
+	}
+	*ts = res
+	return nil
+}
+
+// Value implements sql/driver.Valuer interface. Elements are encoded in ISO 8601
+// format with an explicit UTC offset, which Postgresql parses independently of the
+// session `DateStyle` and `TimeZone` settings.
+func (ts Times) Value() (driver.Value, error) {
+	if ts == nil && NilArrayAsNull() {
+		return nil, nil
+	}
+	b := []byte{openingArray}
+	for i, t := range ts {
+		if i > 0 {
+			b = append(b, arraySeparator)
+		}
+		switch {
+		case !t.Valid:
+			b = append(b, "NULL"...)
+		case t.Inf != Finite:
+			b = append(b, t.Inf.String()...)
+		default:
+			b = append(b, '"')
+			b = appendTimestamp(b, t.Time.Time)
+			b = append(b, '"')
+		}
+	}
+	return string(append(b, closingArray)), nil
+}
+
+// appendTimestamp appends t in the ISO format used by Postgresql:
+// `2006-01-02 15:04:05.999999999+00[ BC]`. Time is converted to UTC.
+func appendTimestamp(b []byte, t time.Time) []byte {
+	t = t.UTC()
+	d := DateOf(t)
+	b = d.appendDate(b)
+	b = append(b, ' ')
+	b = appendInt(b, t.Hour(), 2)
+	b = append(b, ':')
+	b = appendInt(b, t.Minute(), 2)
+	b = append(b, ':')
+	b = appendInt(b, t.Second(), 2)
+	b = appendFraction(b, t.Nanosecond(), 9)
+	b = append(b, "+00"...)
+	if d.Year < 1 {
+		b = append(b, " BC"...)
+	}
+	return b
+}
@@ -0,0 +1,52 @@
+package pgt
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type TimeArraySuite struct{}
+
+func (suite *TimeArraySuite) TestTimesValue(c *C) {
+	loc := time.FixedZone("", 2*3600)
+	ts := Times{
+		NewInfTime(time.Date(2020, 1, 2, 3, 4, 5, 123456000, time.UTC)),
+		{},
+		TimeInfinity,
+		{Time: Time{time.Date(2020, 1, 2, 3, 4, 5, 0, loc), true}},
+		NewInfTime(time.Date(-43, 3, 15, 12, 0, 0, 0, time.UTC)),
+	}
+	v, err := ts.Value()
+	c.Assert(err, IsNil)
+	c.Check(v, Equals, `{"2020-01-02 03:04:05.123456+00",NULL,infinity,`+
+		`"2020-01-02 01:04:05+00","0044-03-15 12:00:00+00 BC"}`)
+
+	var dest Times
+	c.Assert(dest.Scan(v), IsNil)
+	c.Assert(dest, HasLen, len(ts))
+	for i := range ts {
+		c.Check(dest[i].Compare(ts[i]), Equals, 0, Commentf("element %d", i))
+	}
+
+	v, err = Times{}.Value()
+	c.Assert(err, IsNil)
+	c.Check(v, Equals, "{}")
+}
+
+func (suite *TimeArraySuite) TestTimesScan(c *C) {
+	var dest Times
+	src := []byte(`{"2020-01-02 03:04:05+01","Wed Dec 17 07:37:16 1997 PST",NULL,-infinity}`)
+	c.Assert(dest.Scan(src), IsNil)
+	c.Check(dest, DeepEquals, Times{
+		NewInfTime(time.Date(2020, 1, 2, 2, 4, 5, 0, time.UTC)),
+		NewInfTime(time.Date(1997, 12, 17, 15, 37, 16, 0, time.UTC)),
+		{},
+		TimeNegInfinity,
+	})
+
+	c.Check(dest.Scan(`{"2020-01-02 03:04:05 XYZ"}`), NotNil)
+	c.Check(dest.Scan(1), NotNil)
+	c.Check(dest.Scan(nil), IsNil)
+	c.Check(dest, IsNil)
+}
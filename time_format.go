@@ -0,0 +1,285 @@
+package pgt
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// TimeFormat defines JSON encoding of Time values
+type TimeFormat int
+
+// Valid TimeFormat values
+const (
+	// TimeFormatUnix encodes time as a number of seconds since Unix epoch
+	TimeFormatUnix TimeFormat = iota
+	// TimeFormatUnixMilli encodes time as a number of milliseconds since Unix epoch
+	TimeFormatUnixMilli
+	// TimeFormatUnixMicro encodes time as a number of microseconds since Unix epoch
+	TimeFormatUnixMicro
+	// TimeFormatUnixNano encodes time as a number of nanoseconds since Unix
+	// epoch. Only times between the years 1678 and 2262 can be represented.
+	TimeFormatUnixNano
+	// TimeFormatRFC3339 encodes time as RFC 3339 string with second precision
+	TimeFormatRFC3339
+	// TimeFormatRFC3339Milli encodes time as RFC 3339 string with exactly 3
+	// fractional second digits
+	TimeFormatRFC3339Milli
+	// TimeFormatRFC3339Nano encodes time as RFC 3339 string with nanosecond
+	// precision (trailing zeros are removed)
+	TimeFormatRFC3339Nano
+)
+
+var timeJSONFormat atomic.Int32
+
+// SetTimeJSONFormat sets the encoding used by Time.MarshalJSON (Unix seconds by
+// default). It's safe to call it concurrently with encoding and decoding. Use
+// the Time sibling types (eg: TimeRFC3339) to select the encoding per value.
+func SetTimeJSONFormat(f TimeFormat) {
+	timeJSONFormat.Store(int32(f))
+}
+
+// CurrentTimeJSONFormat returns the encoding set by SetTimeJSONFormat
+func CurrentTimeJSONFormat() TimeFormat {
+	return TimeFormat(timeJSONFormat.Load())
+}
+
+const rfc3339Milli = "2006-01-02T15:04:05.000Z07:00"
+
+// marshalJSON encodes t using the format f
+func (f TimeFormat) marshalJSON(t Time) []byte {
+	if !t.Valid {
+		return nullbytes
+	}
+	switch f {
+	case TimeFormatUnixMilli:
+		return strconv.AppendInt(nil, t.UnixMilli(), 10)
+	case TimeFormatUnixMicro:
+		return strconv.AppendInt(nil, t.UnixMicro(), 10)
+	case TimeFormatUnixNano:
+		return strconv.AppendInt(nil, t.UnixNano(), 10)
+	case TimeFormatRFC3339:
+		return appendQuotedTime(t.Time, time.RFC3339)
+	case TimeFormatRFC3339Milli:
+		return appendQuotedTime(t.Time, rfc3339Milli)
+	case TimeFormatRFC3339Nano:
+		return appendQuotedTime(t.Time, time.RFC3339Nano)
+	}
+	return strconv.AppendInt(nil, t.Unix(), 10)
+}
+
+func appendQuotedTime(t time.Time, layout string) []byte {
+	b := make([]byte, 0, len(layout)+4)
+	b = append(b, '"')
+	b = t.AppendFormat(b, layout)
+	return append(b, '"')
+}
+
+// timeFormatAuto is used to decode Unix timestamps of unknown unit, see
+// parseUnixTime
+const timeFormatAuto TimeFormat = -1
+
+// Thresholds used to detect the unit of Unix timestamps of unknown unit. With
+// each unit we can decode dates up to the year 5138.
+const (
+	maxUnixSeconds = 1e11
+	maxUnixMillis  = 1e14
+	maxUnixMicros  = 1e17
+)
+
+// untypedUnixUnit returns the unit of Unix timestamps decoded by Time: the
+// CurrentTimeJSONFormat unit, if it's Unix milli-, micro- or nanoseconds, and
+// timeFormatAuto otherwise.
+func untypedUnixUnit() TimeFormat {
+	switch f := CurrentTimeJSONFormat(); f {
+	case TimeFormatUnixMilli, TimeFormatUnixMicro, TimeFormatUnixNano:
+		return f
+	}
+	return timeFormatAuto
+}
+
+// parseJSONTime decodes time from JSON text (with quotes removed). Accepted
+// formats:
+// * RFC 3339 with an optional fractional seconds and any time offset
+// * Postgresql timestamp text representation (see ParseTime), which includes
+//   the `2006-01-02T15:04:05` format used when aggregated columns are returned
+//   from the DB
+// * Unix timestamps in the unit (see parseUnixTime)
+func parseJSONTime(s string, unit TimeFormat) (Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return NewTime(t), nil
+	}
+	t, err := ParseTime(s)
+	if err == nil {
+		return t, nil
+	}
+	if _, ok := parseInfinity(s); ok {
+		return Time{}, err
+	}
+	return parseUnixTime(s, unit)
+}
+
+// parseUnixTime decodes Unix timestamp in the unit: seconds (optionally with
+// fraction) for TimeFormatUnix, milli-, micro- or nanoseconds for the other
+// Unix formats. For timeFormatAuto the unit is detected from the magnitude of
+// the number and, for compatibility with the previous encoding, 0 and negative
+// timestamps are decoded as NULL.
+func parseUnixTime(s string, unit TimeFormat) (Time, error) {
+	var frac string
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		s, frac = s[:i], s[i+1:]
+	}
+	x, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return Time{}, err
+	}
+	if unit == timeFormatAuto {
+		switch {
+		case x <= 0:
+			return Time{}, nil
+		case x < maxUnixSeconds:
+			unit = TimeFormatUnix
+		case x < maxUnixMillis:
+			unit = TimeFormatUnixMilli
+		case x < maxUnixMicros:
+			unit = TimeFormatUnixMicro
+		default:
+			unit = TimeFormatUnixNano
+		}
+	}
+	if frac != "" && unit != TimeFormatUnix {
+		return Time{}, errors.New("Fractional Unix timestamp is supported only for seconds")
+	}
+	switch unit {
+	case TimeFormatUnixMilli:
+		return NewTime(time.UnixMilli(x)), nil
+	case TimeFormatUnixMicro:
+		return NewTime(time.UnixMicro(x)), nil
+	case TimeFormatUnixNano:
+		return NewTime(time.Unix(0, x)), nil
+	}
+	var nsec int
+	if frac != "" {
+		if nsec, err = parseFraction(frac, 9); err != nil {
+			return Time{}, err
+		}
+		if strings.HasPrefix(s, "-") {
+			nsec = -nsec
+		}
+	}
+	return NewTime(time.Unix(x, int64(nsec))), nil
+}
+
+// unmarshalUnixJSON decodes JSON encoded time with Unix timestamps in the unit
+func unmarshalUnixJSON(data []byte, unit TimeFormat) (Time, error) {
+	if bytes.Equal(data, nullbytes) {
+		return Time{}, nil
+	}
+	return parseJSONTime(strings.Trim(string(data), "\""), unit)
+}
+
+// scanUnix scans integer source as Unix timestamp in the unit, and other
+// sources the same way as Time.Scan
+func scanUnix(src interface{}, unit TimeFormat) (Time, error) {
+	if x, ok := src.(int64); ok {
+		return parseUnixTime(strconv.FormatInt(x, 10), unit)
+	}
+	var t Time
+	err := t.Scan(src)
+	return t, err
+}
+
+// TimeUnixMilli is a Time which is JSON encoded as Unix milliseconds
+type TimeUnixMilli struct{ Time }
+
+// MarshalJSON implements Marshaller interface
+func (t TimeUnixMilli) MarshalJSON() ([]byte, error) {
+	return TimeFormatUnixMilli.marshalJSON(t.Time), nil
+}
+
+// UnmarshalJSON implements Unmarshaller interface. Numbers are decoded as Unix
+// milliseconds, other encodings as in Time.UnmarshalJSON.
+func (t *TimeUnixMilli) UnmarshalJSON(data []byte) (err error) {
+	t.Time, err = unmarshalUnixJSON(data, TimeFormatUnixMilli)
+	return err
+}
+
+// Scan implements Scanner interface. Integers are scanned as Unix milliseconds,
+// other sources as in Time.Scan.
+func (t *TimeUnixMilli) Scan(src interface{}) (err error) {
+	t.Time, err = scanUnix(src, TimeFormatUnixMilli)
+	return err
+}
+
+// TimeUnixMicro is a Time which is JSON encoded as Unix microseconds
+type TimeUnixMicro struct{ Time }
+
+// MarshalJSON implements Marshaller interface
+func (t TimeUnixMicro) MarshalJSON() ([]byte, error) {
+	return TimeFormatUnixMicro.marshalJSON(t.Time), nil
+}
+
+// UnmarshalJSON implements Unmarshaller interface. Numbers are decoded as Unix
+// microseconds, other encodings as in Time.UnmarshalJSON.
+func (t *TimeUnixMicro) UnmarshalJSON(data []byte) (err error) {
+	t.Time, err = unmarshalUnixJSON(data, TimeFormatUnixMicro)
+	return err
+}
+
+// Scan implements Scanner interface. Integers are scanned as Unix microseconds,
+// other sources as in Time.Scan.
+func (t *TimeUnixMicro) Scan(src interface{}) (err error) {
+	t.Time, err = scanUnix(src, TimeFormatUnixMicro)
+	return err
+}
+
+// TimeUnixNano is a Time which is JSON encoded as Unix nanoseconds
+type TimeUnixNano struct{ Time }
+
+// MarshalJSON implements Marshaller interface
+func (t TimeUnixNano) MarshalJSON() ([]byte, error) {
+	return TimeFormatUnixNano.marshalJSON(t.Time), nil
+}
+
+// UnmarshalJSON implements Unmarshaller interface. Numbers are decoded as Unix
+// nanoseconds, other encodings as in Time.UnmarshalJSON.
+func (t *TimeUnixNano) UnmarshalJSON(data []byte) (err error) {
+	t.Time, err = unmarshalUnixJSON(data, TimeFormatUnixNano)
+	return err
+}
+
+// Scan implements Scanner interface. Integers are scanned as Unix nanoseconds,
+// other sources as in Time.Scan.
+func (t *TimeUnixNano) Scan(src interface{}) (err error) {
+	t.Time, err = scanUnix(src, TimeFormatUnixNano)
+	return err
+}
+
+// TimeRFC3339 is a Time which is JSON encoded as RFC 3339 string
+type TimeRFC3339 struct{ Time }
+
+// MarshalJSON implements Marshaller interface
+func (t TimeRFC3339) MarshalJSON() ([]byte, error) {
+	return TimeFormatRFC3339.marshalJSON(t.Time), nil
+}
+
+// TimeRFC3339Milli is a Time which is JSON encoded as RFC 3339 string with
+// millisecond precision
+type TimeRFC3339Milli struct{ Time }
+
+// MarshalJSON implements Marshaller interface
+func (t TimeRFC3339Milli) MarshalJSON() ([]byte, error) {
+	return TimeFormatRFC3339Milli.marshalJSON(t.Time), nil
+}
+
+// TimeRFC3339Nano is a Time which is JSON encoded as RFC 3339 string with
+// nanosecond precision
+type TimeRFC3339Nano struct{ Time }
+
+// MarshalJSON implements Marshaller interface
+func (t TimeRFC3339Nano) MarshalJSON() ([]byte, error) {
+	return TimeFormatRFC3339Nano.marshalJSON(t.Time), nil
+}
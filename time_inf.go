@@ -0,0 +1,225 @@
+package pgt
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"math"
+	"strings"
+	"time"
+
+	bat "github.com/robert-zaremba/go-bat"
+)
+
+// InfTime is a Time which can also represent Postgresql `infinity` and
+// `-infinity` timestamps. A valid InfTime with `Inf` set is infinite, in that
+// case the time.Time value is ignored.
+//
+// Compare, Before, After, Equal, Add and the Unix* methods of InfTime handle
+// infinity (and NULL in comparisons). Use `t.Time.Time` to call the time.Time
+// methods directly.
+type InfTime struct {
+	Time
+	Inf Infinity
+}
+
+// Infinite times
+var (
+	TimeInfinity    = InfTime{Time: Time{Valid: true}, Inf: PosInfinity}
+	TimeNegInfinity = InfTime{Time: Time{Valid: true}, Inf: NegInfinity}
+)
+
+// NewInfTime creates new valid, finite UTC InfTime
+func NewInfTime(t time.Time) InfTime {
+	return InfTime{Time: NewTime(t)}
+}
+
+// ParseInfTime parses timestamp the same way as ParseTime, and the infinity
+// literals.
+func ParseInfTime(s string) (InfTime, error) {
+	return CurrentTimeParser().ParseInfTime(s)
+}
+
+// ParseInfTime parses timestamp the same way as ParseInfTime, using the p
+// settings
+func (p TimeParser) ParseInfTime(s string) (InfTime, error) {
+	if inf, ok := parseInfinity(s); ok {
+		return InfTime{Time: Time{Valid: true}, Inf: inf}, nil
+	}
+	t, err := p.ParseTime(s)
+	return InfTime{Time: t}, err
+}
+
+// IsInfinite returns true if t is `infinity` or `-infinity`
+func (t InfTime) IsInfinite() bool {
+	return t.Inf != Finite
+}
+
+// Compare returns -1, 0 or 1 if t is respectively before, equal or after u.
+// `-infinity` is before and `infinity` after all finite times. NULL is sorted
+// after all other values, as Postgresql does in ascending order.
+func (t InfTime) Compare(u InfTime) int {
+	if t.Valid != u.Valid {
+		if t.Valid {
+			return -1
+		}
+		return 1
+	}
+	if !t.Valid {
+		return 0
+	}
+	if t.Inf != u.Inf {
+		if t.Inf < u.Inf {
+			return -1
+		}
+		return 1
+	}
+	if t.Inf != Finite {
+		return 0
+	}
+	return t.Time.Time.Compare(u.Time.Time)
+}
+
+// Before reports whether t is before u, in the Compare order
+func (t InfTime) Before(u InfTime) bool {
+	return t.Compare(u) < 0
+}
+
+// After reports whether t is after u, in the Compare order
+func (t InfTime) After(u InfTime) bool {
+	return t.Compare(u) > 0
+}
+
+// Equal reports whether t and u represent the same instant, the same infinity
+// or are both NULL
+func (t InfTime) Equal(u InfTime) bool {
+	return t.Compare(u) == 0
+}
+
+// Scan implements Scanner interface. It accepts the Time.Scan sources and the
+// infinity literals.
+func (t *InfTime) Scan(value interface{}) error {
+	switch value.(type) {
+	case []byte, string:
+		s, _ := bat.UnsafeToString(value)
+		parsed, err := ParseInfTime(s)
+		if err != nil {
+			return err
+		}
+		*t = parsed
+		return nil
+	}
+	*t = InfTime{}
+	return t.Time.Scan(value)
+}
+
+// Value implements Valuer interface
+func (t InfTime) Value() (driver.Value, error) {
+	if t.Valid && t.Inf != Finite {
+		return t.Inf.String(), nil
+	}
+	return t.Time.Value()
+}
+
+// MarshalJSON implements Marshaller interface. Infinite values are encoded as
+// `"infinity"` and `"-infinity"` strings, finite values as in Time.MarshalJSON.
+func (t InfTime) MarshalJSON() ([]byte, error) {
+	if t.Valid && t.Inf != Finite {
+		return []byte(`"` + t.Inf.String() + `"`), nil
+	}
+	return t.Time.MarshalJSON()
+}
+
+// UnmarshalJSON implements Unmarshaller interface. It accepts the infinity
+// literals and all encodings accepted by Time.UnmarshalJSON.
+func (t *InfTime) UnmarshalJSON(data []byte) error {
+	if !bytes.Equal(data, nullbytes) {
+		if inf, ok := parseInfinity(strings.Trim(string(data), "\"")); ok {
+			*t = InfTime{Time: Time{Valid: true}, Inf: inf}
+			return nil
+		}
+	}
+	*t = InfTime{}
+	return t.Time.UnmarshalJSON(data)
+}
+
+// MarshalBinary implements binary encoding. Infinite values are encoded in the
+// tag only, other values as in Time.MarshalBinary.
+func (t InfTime) MarshalBinary() ([]byte, error) {
+	if t.Valid {
+		switch t.Inf {
+		case PosInfinity:
+			return []byte{binaryTag(binaryPosInfinity)}, nil
+		case NegInfinity:
+			return []byte{binaryTag(binaryNegInfinity)}, nil
+		}
+	}
+	return t.Time.MarshalBinary()
+}
+
+// UnmarshalBinary implements binary decoding. It accepts all encodings accepted
+// by Time.UnmarshalBinary and the infinite values.
+func (t *InfTime) UnmarshalBinary(data []byte) error {
+	if len(data) == 1 {
+		switch data[0] {
+		case binaryTag(binaryPosInfinity):
+			*t = TimeInfinity
+			return nil
+		case binaryTag(binaryNegInfinity):
+			*t = TimeNegInfinity
+			return nil
+		}
+	}
+	*t = InfTime{}
+	return t.Time.UnmarshalBinary(data)
+}
+
+// Add is a proxy for the time:Time.Add method. Infinite times are returned
+// unchanged.
+func (t InfTime) Add(d time.Duration) InfTime {
+	if t.Inf != Finite {
+		return t
+	}
+	return InfTime{Time: t.Time.Add(d)}
+}
+
+// unixInf returns the Unix time of infinite t: math.MaxInt64 for `infinity` and
+// math.MinInt64 for `-infinity`.
+func (t InfTime) unixInf() int64 {
+	if t.Inf == PosInfinity {
+		return math.MaxInt64
+	}
+	return math.MinInt64
+}
+
+// Unix is a proxy for the time.Time.Unix method. It returns math.MaxInt64 for
+// `infinity` and math.MinInt64 for `-infinity`.
+func (t InfTime) Unix() int64 {
+	if t.Inf != Finite {
+		return t.unixInf()
+	}
+	return t.Time.Unix()
+}
+
+// UnixMilli is a proxy for the time.Time.UnixMilli method. See Unix.
+func (t InfTime) UnixMilli() int64 {
+	if t.Inf != Finite {
+		return t.unixInf()
+	}
+	return t.Time.UnixMilli()
+}
+
+// UnixMicro is a proxy for the time.Time.UnixMicro method. See Unix.
+func (t InfTime) UnixMicro() int64 {
+	if t.Inf != Finite {
+		return t.unixInf()
+	}
+	return t.Time.UnixMicro()
+}
+
+// UnixNano is a proxy for the time.Time.UnixNano method. See Unix.
+func (t InfTime) UnixNano() int64 {
+	if t.Inf != Finite {
+		return t.unixInf()
+	}
+	return t.Time.UnixNano()
+}
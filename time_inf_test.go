@@ -0,0 +1,78 @@
+package pgt
+
+import (
+	"math"
+	"time"
+
+	. "github.com/robert-zaremba/checkers"
+	. "gopkg.in/check.v1"
+)
+
+type InfTimeSuite struct{}
+
+func (suite *InfTimeSuite) TestScanInfinity(c *C) {
+	var t InfTime
+	c.Assert(t.Scan([]byte("infinity")), IsNil)
+	c.Check(t, Equals, TimeInfinity)
+	v, err := t.Value()
+	c.Assert(err, IsNil)
+	c.Check(v, Equals, "infinity")
+
+	c.Assert(t.Scan("-infinity"), IsNil)
+	c.Check(t, Equals, TimeNegInfinity)
+	c.Check(t.Valid, IsTrue)
+
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	c.Assert(t.Scan("2020-01-02 03:04:05"), IsNil)
+	c.Check(t, Equals, NewInfTime(ts))
+	c.Assert(t.Scan(ts), IsNil)
+	c.Check(t, Equals, NewInfTime(ts))
+	v, err = t.Value()
+	c.Assert(err, IsNil)
+	c.Check(v, Equals, ts)
+
+	c.Assert(t.Scan(nil), IsNil)
+	c.Check(t.Valid, IsFalse)
+	c.Check(t.IsInfinite(), IsFalse)
+
+	c.Check(t.Scan(12), NotNil)
+}
+
+func (suite *InfTimeSuite) TestCompare(c *C) {
+	t := NewInfTime(time.Now())
+	c.Check(TimeNegInfinity.Compare(t), Equals, -1)
+	c.Check(TimeInfinity.Compare(t), Equals, 1)
+	c.Check(TimeInfinity.Compare(TimeInfinity), Equals, 0)
+	c.Check(t.Compare(t.Add(time.Second)), Equals, -1)
+	c.Check(t.Compare(InfTime{}), Equals, -1)
+	c.Check(TimeInfinity.Add(time.Hour), Equals, TimeInfinity)
+
+	c.Check(TimeNegInfinity.Before(t), IsTrue)
+	c.Check(TimeInfinity.Before(t), IsFalse)
+	c.Check(TimeInfinity.After(t), IsTrue)
+	c.Check(t.After(t.Add(-time.Second)), IsTrue)
+	c.Check(t.Before(InfTime{}), IsTrue)
+	c.Check(t.Equal(NewInfTime(t.Time.Time.In(time.FixedZone("X", 3600)))), IsTrue)
+	c.Check(TimeInfinity.Equal(TimeInfinity), IsTrue)
+	c.Check(TimeInfinity.Equal(TimeNegInfinity), IsFalse)
+	c.Check(InfTime{}.Equal(InfTime{Time: Time{Time: t.Time.Time}}), IsTrue)
+
+	c.Check(t.Unix(), Equals, t.Time.Unix())
+	c.Check(t.UnixNano(), Equals, t.Time.UnixNano())
+	c.Check(TimeInfinity.Unix(), Equals, int64(math.MaxInt64))
+	c.Check(TimeInfinity.UnixMilli(), Equals, int64(math.MaxInt64))
+	c.Check(TimeNegInfinity.UnixMicro(), Equals, int64(math.MinInt64))
+	c.Check(TimeNegInfinity.UnixNano(), Equals, int64(math.MinInt64))
+}
+
+func (suite *InfTimeSuite) TestJSON(c *C) {
+	var dest InfTime
+	testMarshalJSON(TimeNegInfinity, &dest, c)
+	c.Check(dest, Equals, TimeNegInfinity)
+
+	t := NewInfTime(time.Unix(1577934245, 0))
+	testMarshalJSON(t, &dest, c)
+	c.Check(dest, Equals, t)
+	testMarshalJSON(InfTime{}, &dest, c)
+	c.Check(dest, Equals, InfTime{})
+}
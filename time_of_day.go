@@ -0,0 +1,416 @@
+package pgt
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	bat "github.com/robert-zaremba/go-bat"
+)
+
+const (
+	usecPerSecond = int64(time.Second / time.Microsecond)
+	usecPerDay    = secondsPerDay * usecPerSecond
+)
+
+// TimeOfDay represents Postgresql `time` (without time zone) type. It stores the
+// number of microseconds since midnight. `24:00:00` is a valid value (it's
+// allowed by Postgresql).
+type TimeOfDay struct {
+	Microseconds int64
+	Valid        bool
+}
+
+// TimeOfDayTZ represents Postgresql `timetz` type: time of day with a fixed UTC
+// offset.
+type TimeOfDayTZ struct {
+	TimeOfDay
+	// Offset is a time zone offset in seconds east of UTC
+	Offset int
+}
+
+// NewTimeOfDay creates new valid TimeOfDay. It doesn't validate the arguments,
+// use ParseTimeOfDay to construct TimeOfDay from untrusted input.
+func NewTimeOfDay(hour, min, sec, usec int) TimeOfDay {
+	return TimeOfDay{
+		Microseconds: (int64(hour)*3600+int64(min)*60+int64(sec))*usecPerSecond + int64(usec),
+		Valid:        true}
+}
+
+// TimeOfDayOf returns the clock of t in the t location.
+func TimeOfDayOf(t time.Time) TimeOfDay {
+	return NewTimeOfDay(t.Hour(), t.Minute(), t.Second(), t.Nanosecond()/1000)
+}
+
+// TimeOfDayTZOf returns the clock and zone offset of t in the t location.
+func TimeOfDayTZOf(t time.Time) TimeOfDayTZ {
+	_, offset := t.Zone()
+	return TimeOfDayTZ{TimeOfDayOf(t), offset}
+}
+
+// ParseTimeOfDay parses `HH:MM[:SS[.ffffff]]` time representation.
+// Fractional seconds are rounded to microseconds.
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	t, err := parseClock(s)
+	if err != nil {
+		return t, fmt.Errorf("Can't parse %q as time: %v", s, err)
+	}
+	return t, nil
+}
+
+// ParseTimeOfDayTZ parses `HH:MM[:SS[.ffffff]]` time representation followed by
+// UTC offset in one of the formats: `+HH`, `+HH:MM`, `+HH:MM:SS`, `+HHMM` or `Z`.
+func ParseTimeOfDayTZ(s string) (TimeOfDayTZ, error) {
+	i := strings.IndexAny(s, "+-Z")
+	if i < 0 {
+		return TimeOfDayTZ{}, fmt.Errorf("Can't parse %q as time with time zone: missing offset", s)
+	}
+	t, err := parseClock(s[:i])
+	if err != nil {
+		return TimeOfDayTZ{}, fmt.Errorf("Can't parse %q as time with time zone: %v", s, err)
+	}
+	offset, err := parseOffset(s[i:])
+	if err != nil {
+		return TimeOfDayTZ{}, fmt.Errorf("Can't parse %q as time with time zone: %v", s, err)
+	}
+	return TimeOfDayTZ{t, offset}, nil
+}
+
+// parseClock parses `HH:MM[:SS[.fffffffff]]`
+func parseClock(s string) (TimeOfDay, error) {
+	var sec, frac string
+	parts := strings.Split(s, ":")
+	switch len(parts) {
+	case 2:
+	case 3:
+		sec = parts[2]
+		if i := strings.IndexByte(sec, '.'); i >= 0 {
+			sec, frac = sec[:i], sec[i+1:]
+			if frac == "" {
+				return TimeOfDay{}, errors.New("wrong fractional seconds")
+			}
+		}
+	default:
+		return TimeOfDay{}, errors.New("expecting HH:MM:SS format")
+	}
+	h, err := parseDigits(parts[0])
+	if err != nil || h > 24 {
+		return TimeOfDay{}, errors.New("wrong hour")
+	}
+	m, err := parseDigits(parts[1])
+	if err != nil || m > 59 {
+		return TimeOfDay{}, errors.New("wrong minute")
+	}
+	var secs, usecs int
+	if sec != "" {
+		if secs, err = parseDigits(sec); err != nil || secs > 60 {
+			return TimeOfDay{}, errors.New("wrong second")
+		}
+	}
+	if frac != "" {
+		if usecs, err = parseFraction(frac, 6); err != nil {
+			return TimeOfDay{}, errors.New("wrong fractional seconds")
+		}
+	}
+	t := NewTimeOfDay(h, m, secs, usecs)
+	if t.Microseconds > usecPerDay {
+		return TimeOfDay{}, errors.New("time out of range")
+	}
+	return t, nil
+}
+
+// parseFraction converts fractional part of a number to an integer with the
+// given number of digits, rounding half up.
+func parseFraction(frac string, digits int) (int, error) {
+	if _, err := parseDigits(frac); err != nil {
+		return 0, err
+	}
+	var x int
+	for i := 0; i < digits; i++ {
+		x *= 10
+		if i < len(frac) {
+			x += int(frac[i] - '0')
+		}
+	}
+	if len(frac) > digits && frac[digits] >= '5' {
+		x++
+	}
+	return x, nil
+}
+
+// parseOffset parses UTC offset in seconds: `Z`, `+HH`, `+HH:MM`, `+HH:MM:SS`, `+HHMM`.
+func parseOffset(s string) (int, error) {
+	if s == "Z" {
+		return 0, nil
+	}
+	if len(s) < 2 || (s[0] != '+' && s[0] != '-') {
+		return 0, errors.New("wrong offset")
+	}
+	sign, s := s[0], s[1:]
+	var parts []string
+	if strings.IndexByte(s, ':') >= 0 {
+		parts = strings.Split(s, ":")
+	} else if len(s) == 4 {
+		parts = []string{s[:2], s[2:]}
+	} else {
+		parts = []string{s}
+	}
+	if len(parts) > 3 {
+		return 0, errors.New("wrong offset")
+	}
+	var offset int
+	for i, p := range parts {
+		x, err := parseDigits(p)
+		if err != nil || len(p) > 2 || (i > 0 && x > 59) {
+			return 0, errors.New("wrong offset")
+		}
+		offset = offset*60 + x
+	}
+	for i := len(parts); i < 3; i++ {
+		offset *= 60
+	}
+	if offset > 16*3600 {
+		return 0, errors.New("offset out of range")
+	}
+	if sign == '-' {
+		offset = -offset
+	}
+	return offset, nil
+}
+
+// Hour returns the hour within the day, in the range [0, 24].
+func (t TimeOfDay) Hour() int {
+	return int(t.Microseconds / (3600 * usecPerSecond))
+}
+
+// Minute returns the minute offset within the hour, in the range [0, 59].
+func (t TimeOfDay) Minute() int {
+	return int(t.Microseconds / (60 * usecPerSecond) % 60)
+}
+
+// Second returns the second offset within the minute, in the range [0, 59].
+func (t TimeOfDay) Second() int {
+	return int(t.Microseconds / usecPerSecond % 60)
+}
+
+// Microsecond returns the microsecond offset within the second.
+func (t TimeOfDay) Microsecond() int {
+	return int(t.Microseconds % usecPerSecond)
+}
+
+// Duration returns the time elapsed since midnight
+func (t TimeOfDay) Duration() time.Duration {
+	return time.Duration(t.Microseconds) * time.Microsecond
+}
+
+// On combines the time of day with date d in the location loc: the result has
+// the wall clock t on d, also on days of DST transitions. It returns an invalid
+// InfTime if either t or d is NULL and an infinite InfTime for infinite d.
+func (t TimeOfDay) On(d Date, loc *time.Location) InfTime {
+	if !t.Valid || !d.Valid {
+		return InfTime{}
+	}
+	if d.Inf != Finite {
+		return InfTime{Time: Time{Valid: true}, Inf: d.Inf}
+	}
+	return NewInfTime(time.Date(d.Year, d.Month, d.Day,
+		t.Hour(), t.Minute(), t.Second(), t.Microsecond()*1000, loc))
+}
+
+// OnDateOf combines the time of day with the date of ts in the location loc.
+// See On.
+func (t TimeOfDay) OnDateOf(ts Time, loc *time.Location) Time {
+	if !ts.Valid {
+		return Time{}
+	}
+	return t.On(DateOf(ts.Time.In(loc)), loc).Time
+}
+
+// String returns Postgresql canonical representation of the time.
+// Empty string is returned for NULL.
+func (t TimeOfDay) String() string {
+	if !t.Valid {
+		return ""
+	}
+	return string(t.appendText(nil))
+}
+
+func (t TimeOfDay) appendText(b []byte) []byte {
+	b = appendInt(b, t.Hour(), 2)
+	b = append(b, ':')
+	b = appendInt(b, t.Minute(), 2)
+	b = append(b, ':')
+	b = appendInt(b, t.Second(), 2)
+	return appendFraction(b, t.Microsecond(), 6)
+}
+
+// appendFraction appends `.` and fractional digits of x, without trailing
+// zeros. Nothing is appended if x is 0.
+func appendFraction(b []byte, x, digits int) []byte {
+	if x == 0 {
+		return b
+	}
+	for x%10 == 0 {
+		x /= 10
+		digits--
+	}
+	b = append(b, '.')
+	return appendInt(b, x, digits)
+}
+
+// Scan implements sql.Scanner interface
+func (t *TimeOfDay) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*t = TimeOfDay{}
+		return nil
+	case time.Time:
+		*t = TimeOfDayOf(v)
+		return nil
+	case []byte, string:
+		s, _ := bat.UnsafeToString(src)
+		parsed, err := ParseTimeOfDay(s)
+		if err != nil {
+			return err
+		}
+		*t = parsed
+		return nil
+	}
+	return fmt.Errorf("Scan source was not a time, but %T", src)
+}
+
+// Value implements sql/driver.Valuer interface
+func (t TimeOfDay) Value() (driver.Value, error) {
+	if !t.Valid {
+		return nil, nil
+	}
+	return t.String(), nil
+}
+
+// MarshalJSON implements Marshaller interface
+func (t TimeOfDay) MarshalJSON() ([]byte, error) {
+	if !t.Valid {
+		return nullbytes, nil
+	}
+	b := t.appendText([]byte{'"'})
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON implements Unmarshaller interface
+func (t *TimeOfDay) UnmarshalJSON(data []byte) (err error) {
+	if bytes.Equal(data, nullbytes) {
+		*t = TimeOfDay{}
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return errors.New("expecting string data (value encoded in \"\")")
+	}
+	*t, err = ParseTimeOfDay(string(data[1 : len(data)-1]))
+	return err
+}
+
+// Location returns a fixed time zone with the time offset
+func (t TimeOfDayTZ) Location() *time.Location {
+	return time.FixedZone(formatOffset(t.Offset), t.Offset)
+}
+
+// On combines the time of day with date d in the time offset zone.
+func (t TimeOfDayTZ) On(d Date) InfTime {
+	return t.TimeOfDay.On(d, t.Location())
+}
+
+// OnDateOf combines the time of day with the date of ts in the time offset zone.
+func (t TimeOfDayTZ) OnDateOf(ts Time) Time {
+	return t.TimeOfDay.OnDateOf(ts, t.Location())
+}
+
+// String returns Postgresql canonical representation of the time with time zone.
+// Empty string is returned for NULL.
+func (t TimeOfDayTZ) String() string {
+	if !t.Valid {
+		return ""
+	}
+	return string(t.appendText(nil))
+}
+
+func (t TimeOfDayTZ) appendText(b []byte) []byte {
+	return appendOffset(t.TimeOfDay.appendText(b), t.Offset)
+}
+
+func formatOffset(offset int) string {
+	return string(appendOffset(nil, offset))
+}
+
+// appendOffset appends UTC offset in Postgresql format: `+HH[:MM[:SS]]`
+func appendOffset(b []byte, offset int) []byte {
+	if offset < 0 {
+		b = append(b, '-')
+		offset = -offset
+	} else {
+		b = append(b, '+')
+	}
+	b = appendInt(b, offset/3600, 2)
+	if offset%3600 != 0 {
+		b = append(b, ':')
+		b = appendInt(b, offset/60%60, 2)
+		if offset%60 != 0 {
+			b = append(b, ':')
+			b = appendInt(b, offset%60, 2)
+		}
+	}
+	return b
+}
+
+// Scan implements sql.Scanner interface
+func (t *TimeOfDayTZ) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*t = TimeOfDayTZ{}
+		return nil
+	case time.Time:
+		*t = TimeOfDayTZOf(v)
+		return nil
+	case []byte, string:
+		s, _ := bat.UnsafeToString(src)
+		parsed, err := ParseTimeOfDayTZ(s)
+		if err != nil {
+			return err
+		}
+		*t = parsed
+		return nil
+	}
+	return fmt.Errorf("Scan source was not a time, but %T", src)
+}
+
+// Value implements sql/driver.Valuer interface
+func (t TimeOfDayTZ) Value() (driver.Value, error) {
+	if !t.Valid {
+		return nil, nil
+	}
+	return t.String(), nil
+}
+
+// MarshalJSON implements Marshaller interface
+func (t TimeOfDayTZ) MarshalJSON() ([]byte, error) {
+	if !t.Valid {
+		return nullbytes, nil
+	}
+	b := t.appendText([]byte{'"'})
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON implements Unmarshaller interface
+func (t *TimeOfDayTZ) UnmarshalJSON(data []byte) (err error) {
+	if bytes.Equal(data, nullbytes) {
+		*t = TimeOfDayTZ{}
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return errors.New("expecting string data (value encoded in \"\")")
+	}
+	*t, err = ParseTimeOfDayTZ(string(data[1 : len(data)-1]))
+	return err
+}
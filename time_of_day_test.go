@@ -0,0 +1,112 @@
+package pgt
+
+import (
+	"time"
+
+	. "github.com/robert-zaremba/checkers"
+	. "gopkg.in/check.v1"
+)
+
+type TimeOfDaySuite struct{}
+
+func (suite *TimeOfDaySuite) TestParseTimeOfDay(c *C) {
+	testCases := []struct {
+		in, out string
+		t       TimeOfDay
+	}{
+		{"10:20", "10:20:00", NewTimeOfDay(10, 20, 0, 0)},
+		{"10:20:30", "10:20:30", NewTimeOfDay(10, 20, 30, 0)},
+		{"10:20:30.5", "10:20:30.5", NewTimeOfDay(10, 20, 30, 500000)},
+		{"00:00:00.000001", "00:00:00.000001", NewTimeOfDay(0, 0, 0, 1)},
+		{"10:20:30.1234567", "10:20:30.123457", NewTimeOfDay(10, 20, 30, 123457)},
+		{"24:00:00", "24:00:00", NewTimeOfDay(24, 0, 0, 0)},
+	}
+	for _, tc := range testCases {
+		t, err := ParseTimeOfDay(tc.in)
+		c.Assert(err, IsNil, Comment(tc.in))
+		c.Check(t, Equals, tc.t)
+		c.Check(t.String(), Equals, tc.out)
+	}
+
+	for _, s := range []string{"", "10", "24:00:01", "10:60:00", "10:20:30.", "1:2:3:4", "aa:bb"} {
+		_, err := ParseTimeOfDay(s)
+		c.Check(err, NotNil, Comment(s))
+	}
+}
+
+func (suite *TimeOfDaySuite) TestParseTimeOfDayTZ(c *C) {
+	testCases := []struct {
+		in, out string
+		offset  int
+	}{
+		{"10:20:30+00", "10:20:30+00", 0},
+		{"10:20:30Z", "10:20:30+00", 0},
+		{"10:20:30+05:30", "10:20:30+05:30", 5*3600 + 30*60},
+		{"10:20:30+0530", "10:20:30+05:30", 5*3600 + 30*60},
+		{"10:20:30.25-03", "10:20:30.25-03", -3 * 3600},
+		{"10:20:30-00:30:15", "10:20:30-00:30:15", -(30*60 + 15)},
+	}
+	for _, tc := range testCases {
+		t, err := ParseTimeOfDayTZ(tc.in)
+		c.Assert(err, IsNil, Comment(tc.in))
+		c.Check(t.Offset, Equals, tc.offset)
+		c.Check(t.String(), Equals, tc.out)
+	}
+
+	for _, s := range []string{"10:20:30", "10:20:30+", "10:20:30+5:300", "10:20:30+05:60"} {
+		_, err := ParseTimeOfDayTZ(s)
+		c.Check(err, NotNil, Comment(s))
+	}
+}
+
+func (suite *TimeOfDaySuite) TestTimeOfDayCombine(c *C) {
+	d := NewDate(2020, time.March, 1)
+	t := NewTimeOfDay(10, 30, 0, 0)
+	c.Check(t.On(d, time.UTC).Time.Time, Equals, time.Date(2020, 3, 1, 10, 30, 0, 0, time.UTC))
+	c.Check(NewTimeOfDay(24, 0, 0, 0).On(d, time.UTC).Time.Time,
+		Equals, time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC))
+	c.Check(t.On(Date{}, time.UTC).Valid, IsFalse)
+	c.Check(t.On(DateInfinity, time.UTC), Equals, TimeInfinity)
+
+	tz, err := ParseTimeOfDayTZ("10:30:00+02")
+	c.Assert(err, IsNil)
+	c.Check(tz.On(d).Time.Time, Equals, time.Date(2020, 3, 1, 8, 30, 0, 0, time.UTC))
+
+	ts := NewTime(time.Date(2020, 3, 1, 23, 0, 0, 0, time.UTC))
+	c.Check(tz.OnDateOf(ts).Time, Equals, time.Date(2020, 3, 2, 8, 30, 0, 0, time.UTC))
+}
+
+func (suite *TimeOfDaySuite) TestTimeOfDayCombineDST(c *C) {
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	c.Assert(err, IsNil)
+	t := NewTimeOfDay(9, 0, 0, 0)
+	// clocks moved forward at 02:00 on 2020-03-29 and back at 03:00 on 2020-10-25
+	for _, d := range []Date{NewDate(2020, time.March, 29), NewDate(2020, time.October, 25)} {
+		ts := t.On(d, warsaw)
+		c.Check(ts.Time.Time.Equal(time.Date(d.Year, d.Month, d.Day, 9, 0, 0, 0, warsaw)), IsTrue)
+		c.Check(TimeOfDayOf(ts.Time.Time.In(warsaw)), Equals, t)
+		c.Check(t.OnDateOf(ts.Time, warsaw), Equals, ts.Time)
+	}
+	c.Check(t.On(NewDate(2020, time.March, 29), warsaw).Time.Time, Equals, time.Date(2020, 3, 29, 7, 0, 0, 0, time.UTC))
+	c.Check(t.On(NewDate(2020, time.October, 25), warsaw).Time.Time, Equals, time.Date(2020, 10, 25, 8, 0, 0, 0, time.UTC))
+}
+
+func (suite *TimeOfDaySuite) TestTimeOfDayScan(c *C) {
+	var t TimeOfDay
+	c.Assert(t.Scan([]byte("08:00:00")), IsNil)
+	c.Check(t, Equals, NewTimeOfDay(8, 0, 0, 0))
+	c.Assert(t.Scan(time.Date(0, 1, 1, 9, 15, 0, 0, time.UTC)), IsNil)
+	c.Check(t, Equals, NewTimeOfDay(9, 15, 0, 0))
+	v, err := t.Value()
+	c.Assert(err, IsNil)
+	c.Check(v, Equals, "09:15:00")
+	c.Assert(t.Scan(nil), IsNil)
+	c.Check(t.Valid, IsFalse)
+
+	var tz TimeOfDayTZ
+	c.Assert(tz.Scan("08:00:00+01"), IsNil)
+	c.Check(tz.Offset, Equals, 3600)
+	var dest TimeOfDayTZ
+	testMarshalJSON(tz, &dest, c)
+	c.Check(dest, Equals, tz)
+}
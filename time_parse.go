@@ -0,0 +1,229 @@
+package pgt
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DateOrder defines the order of day and month fields in dates
+type DateOrder int
+
+// Valid DateOrder values
+const (
+	DateOrderMDY DateOrder = iota
+	DateOrderDMY
+)
+
+// defaultTimeZoneAbbrevs is a subset of the Postgresql `Default` abbreviations
+// set, without the ambiguous abbreviations. Offsets are in seconds east of UTC.
+var defaultTimeZoneAbbrevs = map[string]int{
+	"UTC": 0, "UT": 0, "GMT": 0, "Z": 0, "WET": 0,
+	"WEST": 1 * 3600, "BST": 1 * 3600, "CET": 1 * 3600, "MET": 1 * 3600,
+	"CEST": 2 * 3600, "MEST": 2 * 3600, "EET": 2 * 3600, "SAST": 2 * 3600,
+	"EEST": 3 * 3600, "MSK": 3 * 3600,
+	"AWST": 8 * 3600, "HKT": 8 * 3600,
+	"JST": 9 * 3600, "KST": 9 * 3600, "ACST": 9*3600 + 1800,
+	"AEST": 10 * 3600, "ACDT": 10*3600 + 1800, "AEDT": 11 * 3600,
+	"NZST": 12 * 3600, "NZDT": 13 * 3600,
+	"HST": -10 * 3600, "AKST": -9 * 3600, "AKDT": -8 * 3600,
+	"PST": -8 * 3600, "PDT": -7 * 3600, "MST": -7 * 3600, "MDT": -6 * 3600,
+	"CST": -6 * 3600, "CDT": -5 * 3600, "EST": -5 * 3600, "EDT": -4 * 3600,
+}
+
+// DefaultTimeZoneAbbrevs returns a copy of the default time zone abbreviations
+// (a subset of the Postgresql `Default` set, without the ambiguous
+// abbreviations), which can be extended and set in TimeParser.
+func DefaultTimeZoneAbbrevs() map[string]int {
+	return maps.Clone(defaultTimeZoneAbbrevs)
+}
+
+// TimeParser defines the session settings needed to parse timestamps and dates
+// in all the Postgresql `DateStyle` output formats. Zero value uses the `MDY`
+// order and the default time zone abbreviations.
+type TimeParser struct {
+	// DateOrder is the field order used to parse dates in the `SQL` and
+	// `Postgres` styles (eg: `12/17/1997`, `12-17-1997`). It must match the
+	// second component of the session `DateStyle` setting. It's not used for
+	// the `ISO` and `German` styles, which are unambiguous.
+	DateOrder DateOrder
+	// ZoneAbbrevs maps time zone abbreviations to their offsets in seconds east
+	// of UTC. It's used to parse timestamps with time zone in the `SQL`,
+	// `Postgres` and `German` styles, which print zone abbreviation instead of an
+	// offset. Nil means DefaultTimeZoneAbbrevs. The map must not be modified
+	// while the parser is in use.
+	ZoneAbbrevs map[string]int
+}
+
+var timeParser atomic.Pointer[TimeParser]
+
+// SetTimeParser sets settings used by ParseTime, ParseDate and the Scan methods
+// of the time types. It's safe to call it concurrently with parsing: p (with a
+// copy of p.ZoneAbbrevs) is used by the calls started after SetTimeParser.
+func SetTimeParser(p TimeParser) {
+	p.ZoneAbbrevs = maps.Clone(p.ZoneAbbrevs)
+	timeParser.Store(&p)
+}
+
+// CurrentTimeParser returns settings set by SetTimeParser
+func CurrentTimeParser() TimeParser {
+	if p := timeParser.Load(); p != nil {
+		return *p
+	}
+	return TimeParser{}
+}
+
+var monthAbbrevs = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March,
+	"apr": time.April, "may": time.May, "jun": time.June,
+	"jul": time.July, "aug": time.August, "sep": time.September,
+	"oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+// ParseTime parses Postgresql `timestamp` and `timestamptz` text representation in
+// any of the `DateStyle` output formats:
+// * ISO: `1997-12-17 07:37:16.123456-08` (`T` is accepted as date and time
+//   separator as well)
+// * SQL: `12/17/1997 07:37:16.00 PST`
+// * Postgres: `Wed Dec 17 07:37:16 1997 PST`
+// * German: `17.12.1997 07:37:16.00 PST`
+// All formats support ` BC` suffix. Time zone can be a numeric offset or an
+// abbreviation. Timestamps without time zone are interpreted as UTC. Settings
+// set by SetTimeParser are used for the `SQL` and `Postgres` field order and
+// the zone abbreviations. The infinity literals are rejected, use ParseInfTime
+// to parse them.
+func ParseTime(s string) (Time, error) {
+	return CurrentTimeParser().ParseTime(s)
+}
+
+// ParseTime parses timestamp the same way as ParseTime, using the p settings
+func (p TimeParser) ParseTime(s string) (Time, error) {
+	if _, ok := parseInfinity(s); ok {
+		return Time{}, fmt.Errorf("Can't parse %q as Time, use InfTime for infinite timestamps", s)
+	}
+	t, err := p.parseTimestamp(s)
+	if err != nil {
+		return Time{}, fmt.Errorf("Can't parse %q as timestamp: %v", s, err)
+	}
+	return NewTime(t), nil
+}
+
+func (p TimeParser) parseTimestamp(s string) (time.Time, error) {
+	s, bc := trimBC(s)
+	fields := strings.Fields(s)
+	if len(fields) == 1 { // ISO 8601 with `T` separator
+		if i := strings.IndexByte(s, 'T'); i > 0 {
+			fields = []string{s[:i], s[i+1:]}
+		}
+	}
+	var y, d int
+	var m time.Month
+	var err error
+	if len(fields) > 0 && fields[0] != "" && isLetter(fields[0][0]) {
+		y, m, d, fields, err = parsePostgresStyleDate(fields, bc)
+	} else if len(fields) >= 2 {
+		y, m, d, err = p.parseDatePart(fields[0], bc)
+		fields = fields[1:]
+	} else {
+		err = errors.New("expecting date and time")
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// fields[0] is a clock with optional offset, fields[1] is an optional zone
+	clock, zone := fields[0], ""
+	if j := strings.IndexAny(clock, "+-Z"); j >= 0 {
+		clock, zone = clock[:j], clock[j:]
+	}
+	switch {
+	case len(fields) == 2 && zone == "":
+		zone = fields[1]
+	case len(fields) > 1:
+		return time.Time{}, errors.New("unexpected text after time")
+	}
+	tod, err := parseClock(clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc := time.UTC
+	if zone != "" {
+		offset, err := p.parseZone(zone)
+		if err != nil {
+			return time.Time{}, err
+		}
+		loc = time.FixedZone("", offset)
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, loc).Add(tod.Duration()), nil
+}
+
+// parsePostgresStyleDate parses date from `Postgres` style timestamp fields:
+// `Wed Dec 17 07:37:16 1997 PST` or `Wed 17 Dec 07:37:16 1997 PST`. It returns the
+// remaining fields: clock and optional zone.
+func parsePostgresStyleDate(fields []string, bc bool) (int, time.Month, int, []string, error) {
+	if len(fields) < 5 || len(fields) > 6 {
+		return 0, 0, 0, nil, errors.New("expecting Postgres style timestamp")
+	}
+	month, day := fields[1], fields[2]
+	if len(day) > 0 && isLetter(day[0]) {
+		month, day = day, month
+	}
+	m, ok := monthAbbrevs[strings.ToLower(month)]
+	if !ok {
+		return 0, 0, 0, nil, errors.New("wrong month")
+	}
+	y, mm, d, err := parseDateFields(fields[4], strconv.Itoa(int(m)), day, bc)
+	if err != nil {
+		return 0, 0, 0, nil, err
+	}
+	return y, mm, d, append(fields[3:4:4], fields[5:]...), nil
+}
+
+// parseDatePart parses date in the ISO (`1997-12-17`), SQL (`12/17/1997`),
+// German (`17.12.1997`) or Postgres (`12-17-1997`) style. It returns astronomical
+// year.
+func (p TimeParser) parseDatePart(s string, bc bool) (int, time.Month, int, error) {
+	var parts []string
+	var order = p.DateOrder
+	switch {
+	case strings.IndexByte(s, '/') >= 0:
+		parts = strings.Split(s, "/")
+	case strings.IndexByte(s, '.') >= 0:
+		parts, order = strings.Split(s, "."), DateOrderDMY
+	default:
+		parts = strings.Split(s, "-")
+		if len(parts) == 3 && len(parts[0]) > 2 {
+			return parseDateFields(parts[0], parts[1], parts[2], bc)
+		}
+	}
+	if len(parts) != 3 {
+		return 0, 0, 0, errors.New("wrong date format")
+	}
+	if order == DateOrderDMY {
+		return parseDateFields(parts[2], parts[1], parts[0], bc)
+	}
+	return parseDateFields(parts[2], parts[0], parts[1], bc)
+}
+
+// parseZone parses numeric time zone offset or zone abbreviation
+func (p TimeParser) parseZone(zone string) (int, error) {
+	if zone[0] == '+' || zone[0] == '-' {
+		return parseOffset(zone)
+	}
+	abbrevs := p.ZoneAbbrevs
+	if abbrevs == nil {
+		abbrevs = defaultTimeZoneAbbrevs
+	}
+	if offset, ok := abbrevs[strings.ToUpper(zone)]; ok {
+		return offset, nil
+	}
+	return 0, fmt.Errorf("unknown time zone abbreviation %q", zone)
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
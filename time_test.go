@@ -1,6 +1,7 @@
 package pgt
 
 import (
+	"encoding/json"
 	"time"
 
 	. "github.com/robert-zaremba/checkers"
@@ -36,3 +37,249 @@ func (suite *TimeSuite) TestTimeMarshalJSON(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(t2.Valid, IsFalse)
 }
+
+func (suite *TimeSuite) TestTimeRejectsInfinity(c *C) {
+	var t Time
+	c.Check(t.Scan([]byte("infinity")), ErrorMatches, ".*use InfTime.*")
+	c.Check(t.Scan("-infinity"), ErrorMatches, ".*use InfTime.*")
+	c.Check(t.UnmarshalJSON([]byte(`"infinity"`)), ErrorMatches, ".*use InfTime.*")
+	c.Check(t.Valid, IsFalse)
+
+	c.Check(t.Scan(12), NotNil)
+}
+
+func (suite *TimeSuite) TestTimeMethods(c *C) {
+	// time.Time methods are promoted
+	t := NewTime(time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC))
+	c.Check(t.Before(time.Now()), IsTrue)
+	c.Check(t.After(t.Time.Add(-time.Second)), IsTrue)
+	c.Check(t.Equal(t.Time.In(time.FixedZone("X", 3600))), IsTrue)
+	c.Check(t.Compare(t.Time), Equals, 0)
+	c.Check(t.UnixMilli(), Equals, int64(1577934245000))
+	c.Check(t.Add(time.Second), Equals, NewTime(t.Time.Add(time.Second)))
+	c.Check(Time{}.Add(time.Second).Valid, IsFalse)
+}
+
+func (suite *TimeSuite) TestParseTime(c *C) {
+	testCases := []struct {
+		in  string
+		out time.Time
+	}{
+		{"2020-01-02 03:04:05", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"2020-01-02T03:04:05.123456+02", time.Date(2020, 1, 2, 1, 4, 5, 123456000, time.UTC)},
+		{"2020-01-02 03:04:05-05:30", time.Date(2020, 1, 2, 8, 34, 5, 0, time.UTC)},
+		{"0044-03-15 12:00:00 BC", time.Date(-43, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{"0001-02-29 10:00:00+01 BC", time.Date(0, 2, 29, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range testCases {
+		t, err := ParseTime(tc.in)
+		c.Assert(err, IsNil, Comment(tc.in))
+		c.Check(t.Time, Equals, tc.out, Comment(tc.in))
+		c.Check(t.Valid, IsTrue)
+	}
+
+	for _, s := range []string{"", "2020-01-02", "2020-01-02 25:00:00", "2020-01-02 10:00:00+1:2:3:4"} {
+		_, err := ParseTime(s)
+		c.Check(err, NotNil, Comment(s))
+	}
+}
+
+func (suite *TimeSuite) TestTimeJSONFormats(c *C) {
+	t := NewTime(time.Date(2020, 1, 2, 3, 4, 5, 123456789, time.UTC))
+	testCases := []struct {
+		v   interface{}
+		out string
+		acc time.Duration
+	}{
+		{t, "1577934245", time.Second},
+		{TimeUnixMilli{t}, "1577934245123", time.Millisecond},
+		{TimeUnixMicro{t}, "1577934245123456", time.Microsecond},
+		{TimeUnixNano{t}, "1577934245123456789", 0},
+		{TimeRFC3339{t}, `"2020-01-02T03:04:05Z"`, time.Second},
+		{TimeRFC3339Milli{t}, `"2020-01-02T03:04:05.123Z"`, time.Millisecond},
+		{TimeRFC3339Nano{t}, `"2020-01-02T03:04:05.123456789Z"`, 0},
+	}
+	for _, tc := range testCases {
+		b, err := json.Marshal(tc.v)
+		c.Assert(err, IsNil)
+		c.Check(string(b), Equals, tc.out)
+
+		var dest Time
+		c.Assert(json.Unmarshal(b, &dest), IsNil, Comment(tc.out))
+		c.Check(dest.Time, Equals, t.Time.Truncate(tc.acc), Comment(tc.out))
+	}
+
+	var dest TimeRFC3339Milli
+	c.Assert(json.Unmarshal([]byte(`"2020-01-02T05:04:05.5+02:00"`), &dest), IsNil)
+	c.Check(dest.Time.Time, Equals, time.Date(2020, 1, 2, 3, 4, 5, 5e8, time.UTC))
+	c.Assert(json.Unmarshal([]byte(`"2020-01-02T03:04:05"`), &dest), IsNil)
+	c.Check(dest.Time.Time, Equals, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	c.Assert(json.Unmarshal([]byte(`1577934245.25`), &dest), IsNil)
+	c.Check(dest.Time.Time, Equals, time.Date(2020, 1, 2, 3, 4, 5, 25e7, time.UTC))
+	c.Assert(json.Unmarshal([]byte(`null`), &dest), IsNil)
+	c.Check(dest.Valid, IsFalse)
+	c.Check(json.Unmarshal([]byte(`"yesterday"`), &dest), NotNil)
+}
+
+func (suite *TimeSuite) TestTimeJSONUnixUnits(c *C) {
+	for _, ts := range []time.Time{
+		time.Date(1972, 6, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC),
+		time.Date(1900, 1, 2, 3, 4, 5, 0, time.UTC),
+	} {
+		t := NewTime(ts)
+		cm := Commentf("%v", ts)
+
+		var milli TimeUnixMilli
+		testMarshalJSON(TimeUnixMilli{t}, &milli, c)
+		c.Check(milli.Time, Equals, t, cm)
+		var micro TimeUnixMicro
+		testMarshalJSON(TimeUnixMicro{t}, &micro, c)
+		c.Check(micro.Time, Equals, t, cm)
+		var nano TimeUnixNano
+		testMarshalJSON(TimeUnixNano{t}, &nano, c)
+		c.Check(nano.Time, Equals, t, cm)
+
+		c.Assert(milli.Scan(t.UnixMilli()), IsNil)
+		c.Check(milli.Time, Equals, t, cm)
+		c.Assert(micro.Scan(t.UnixMicro()), IsNil)
+		c.Check(micro.Time, Equals, t, cm)
+	}
+
+	var milli TimeUnixMilli
+	c.Assert(json.Unmarshal([]byte(`-1500`), &milli), IsNil)
+	c.Check(milli.Time.Time, Equals, time.Date(1969, 12, 31, 23, 59, 58, 5e8, time.UTC))
+	c.Assert(json.Unmarshal([]byte(`"2020-01-02T03:04:05Z"`), &milli), IsNil)
+	c.Check(milli.Time.Time, Equals, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	c.Check(json.Unmarshal([]byte(`1.5`), &milli), NotNil)
+	c.Assert(json.Unmarshal([]byte(`null`), &milli), IsNil)
+	c.Check(milli.Valid, IsFalse)
+	c.Assert(milli.Scan(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)), IsNil)
+	c.Check(milli.Time.Time, Equals, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC))
+
+	// untyped Time detects the unit, unless SetTimeJSONFormat selects it
+	var dest Time
+	c.Assert(json.Unmarshal([]byte(`-1.5`), &dest), IsNil)
+	c.Check(dest.Valid, IsFalse)
+	c.Assert(json.Unmarshal([]byte(`70000000000`), &dest), IsNil)
+	c.Check(dest.Time, Equals, time.Unix(70000000000, 0).UTC())
+	defer SetTimeJSONFormat(CurrentTimeJSONFormat())
+	SetTimeJSONFormat(TimeFormatUnixMilli)
+	t := NewTime(time.Date(1972, 6, 1, 0, 0, 0, 0, time.UTC))
+	testMarshalJSON(t, &dest, c)
+	c.Check(dest, Equals, t)
+	c.Assert(json.Unmarshal([]byte(`-1500`), &dest), IsNil)
+	c.Check(dest.Time, Equals, time.Date(1969, 12, 31, 23, 59, 58, 5e8, time.UTC))
+}
+
+func (suite *TimeSuite) TestTimeJSONUnixOutOfNanoRange(c *C) {
+	// Unix nanoseconds overflow int64 outside of the years 1678-2262
+	testCases := []struct {
+		t            time.Time
+		milli, micro string
+	}{
+		{time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC), "32503680000000", "32503680000000000"},
+		{time.Date(-500, 3, 15, 12, 0, 0, 0, time.UTC), "-77939323200000", "-77939323200000000"},
+	}
+	for _, tc := range testCases {
+		t := NewTime(tc.t)
+		cm := Commentf("%v", tc.t)
+
+		b, err := json.Marshal(TimeUnixMilli{t})
+		c.Assert(err, IsNil)
+		c.Check(string(b), Equals, tc.milli, cm)
+		var milli TimeUnixMilli
+		c.Assert(json.Unmarshal([]byte(tc.milli), &milli), IsNil)
+		c.Check(milli.Time, Equals, t, cm)
+
+		b, err = json.Marshal(TimeUnixMicro{t})
+		c.Assert(err, IsNil)
+		c.Check(string(b), Equals, tc.micro, cm)
+		var micro TimeUnixMicro
+		c.Assert(json.Unmarshal([]byte(tc.micro), &micro), IsNil)
+		c.Check(micro.Time, Equals, t, cm)
+	}
+}
+
+func (suite *TimeSuite) TestParseUnixTimeFraction(c *C) {
+	t, err := parseUnixTime("-0.5", TimeFormatUnix)
+	c.Assert(err, IsNil)
+	c.Check(t.Time, Equals, time.Date(1969, 12, 31, 23, 59, 59, 5e8, time.UTC))
+	t, err = parseUnixTime("-1.25", TimeFormatUnix)
+	c.Assert(err, IsNil)
+	c.Check(t.Time, Equals, time.Date(1969, 12, 31, 23, 59, 58, 75e7, time.UTC))
+}
+
+func (suite *TimeSuite) TestSetTimeParser(c *C) {
+	defer SetTimeParser(CurrentTimeParser())
+	abbrevs := map[string]int{"XYZ": 3600}
+	SetTimeParser(TimeParser{DateOrder: DateOrderDMY, ZoneAbbrevs: abbrevs})
+	abbrevs["XYZ"] = 0 // SetTimeParser copies the map
+	var t Time
+	c.Assert(t.Scan("17/12/1997 16:37:16.50 XYZ"), IsNil)
+	c.Check(t.Time, Equals, time.Date(1997, 12, 17, 15, 37, 16, 5e8, time.UTC))
+	d, err := ParseDate("17/12/1997")
+	c.Assert(err, IsNil)
+	c.Check(d, Equals, NewDate(1997, time.December, 17))
+}
+
+func (suite *TimeSuite) TestParseTimeDateStyles(c *C) {
+	utc := time.Date(1997, 12, 17, 15, 37, 16, 500000000, time.UTC)
+	for _, s := range []string{
+		"1997-12-17 07:37:16.5-08",
+		"1997-12-17T15:37:16.5Z",
+		"12/17/1997 07:37:16.50 PST",
+		"Wed Dec 17 07:37:16.5 1997 PST",
+		"Wed 17 Dec 16:37:16.5 1997 CET",
+		"17.12.1997 15:37:16.50 UTC",
+		"17.12.1997 18:37:16.50 +03",
+		"12-17-1997 15:37:16.5",
+	} {
+		t, err := ParseTime(s)
+		c.Assert(err, IsNil, Comment(s))
+		c.Check(t.Time, Equals, utc, Comment(s))
+	}
+
+	t, err := ParseTime("Sat Mar 15 12:00:00 0044 UTC BC")
+	c.Assert(err, IsNil)
+	c.Check(t.Time, Equals, time.Date(-43, 3, 15, 12, 0, 0, 0, time.UTC))
+
+	dmy := TimeParser{DateOrder: DateOrderDMY}
+	t, err = dmy.ParseTime("17/12/1997 15:37:16.50")
+	c.Assert(err, IsNil)
+	c.Check(t.Time, Equals, utc)
+	d, err := dmy.ParseDate("17/12/1997")
+	c.Assert(err, IsNil)
+	c.Check(d, Equals, NewDate(1997, time.December, 17))
+
+	abbrevs := DefaultTimeZoneAbbrevs()
+	abbrevs["IST"] = 5*3600 + 1800
+	ist := TimeParser{ZoneAbbrevs: abbrevs}
+	t, err = ist.ParseTime("12/17/1997 21:07:16.50 IST")
+	c.Assert(err, IsNil)
+	c.Check(t.Time, Equals, utc)
+	_, err = ParseTime("12/17/1997 21:07:16.50 IST")
+	c.Check(err, NotNil)
+	_, ok := defaultTimeZoneAbbrevs["IST"]
+	c.Check(ok, IsFalse)
+
+	for _, s := range []string{"17/12/1997 15:37:16", "12/17/1997 15:37:16 XYZ",
+		"Wed Foo 17 07:37:16 1997", "1997-12-17 07:37:16 UTC extra"} {
+		_, err := ParseTime(s)
+		c.Check(err, NotNil, Comment(s))
+	}
+}
+
+func (suite *TimeSuite) TestTimeScanSources(c *C) {
+	var t Time
+	c.Assert(t.Scan([]byte("2020-01-02 03:04:05+00")), IsNil)
+	c.Check(t.Time, Equals, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	c.Check(t.Valid, IsTrue)
+
+	c.Check(t.Scan("not a time"), NotNil)
+	for _, src := range []interface{}{int64(12), 1.5, true} {
+		err := t.Scan(src)
+		c.Check(err, NotNil, Comment(src))
+	}
+}
@@ -0,0 +1,211 @@
+package pgt
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	bat "github.com/robert-zaremba/go-bat"
+)
+
+// ZonedTime is a Time which carries the IANA time zone name (eg: `Europe/Warsaw`)
+// of the original value. Time is stored in UTC (as for the Time type), while Zone
+// allows to restore the local wall time.
+//
+// Postgresql doesn't store time zones in `timestamptz`, so ZonedTime is usually
+// persisted in two columns: `timestamptz` and `text`. Use ScanTargets and Args to
+// bind them, or ZonedTimeRecord for a composite type column. Scan and Value
+// return an error, so the zone is never dropped silently.
+type ZonedTime struct {
+	Time
+	// Zone is IANA time zone name. Empty zone means UTC.
+	Zone string
+}
+
+var locations sync.Map // zone name -> *time.Location
+
+// loadLocation returns cached time.Location of the IANA zone name
+func loadLocation(zone string) (*time.Location, error) {
+	if zone == "" {
+		return time.UTC, nil
+	}
+	if loc, ok := locations.Load(zone); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(zone, loc)
+	return loc, nil
+}
+
+// NewZonedTime creates new valid ZonedTime with the given IANA time zone name.
+// It returns error if the zone is unknown.
+func NewZonedTime(t time.Time, zone string) (ZonedTime, error) {
+	if _, err := loadLocation(zone); err != nil {
+		return ZonedTime{}, err
+	}
+	return ZonedTime{NewTime(t), zone}, nil
+}
+
+// Location returns the time zone of z. UTC is returned if the zone is unknown.
+func (z ZonedTime) Location() *time.Location {
+	loc, err := loadLocation(z.Zone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Local returns the wall time of z in its time zone
+func (z ZonedTime) Local() time.Time {
+	return z.Time.Time.In(z.Location())
+}
+
+// ScanTargets returns sql.Scanner destinations for the timestamp and the zone
+// columns, to be used with sql.Rows.Scan:
+//
+//	rows.Scan(append([]interface{}{&id}, z.ScanTargets()...)...)
+func (z *ZonedTime) ScanTargets() []interface{} {
+	return []interface{}{&z.Time, (*zoneScanner)(z)}
+}
+
+// Args returns the timestamp and the zone query arguments
+func (z ZonedTime) Args() []interface{} {
+	zone := String{}
+	if z.Valid {
+		zone = NewString(z.Zone, false)
+	}
+	return []interface{}{z.Time, zone}
+}
+
+// Scan implements sql.Scanner interface. It always returns an error: a single
+// column can't hold the zone, use ScanTargets or ZonedTimeRecord instead.
+func (z *ZonedTime) Scan(src interface{}) error {
+	return errors.New("Can't scan ZonedTime from a single column, use ScanTargets or ZonedTimeRecord")
+}
+
+// Value implements sql/driver.Valuer interface. It always returns an error: a
+// single argument can't hold the zone, use Args or ZonedTimeRecord instead.
+func (z ZonedTime) Value() (driver.Value, error) {
+	return nil, errors.New("Can't use ZonedTime as a single argument, use Args or ZonedTimeRecord")
+}
+
+type zoneScanner ZonedTime
+
+// Scan implements sql.Scanner for the zone column
+func (z *zoneScanner) Scan(src interface{}) error {
+	var s String
+	if err := s.Scan(src); err != nil {
+		return err
+	}
+	if _, err := loadLocation(s.String); err != nil {
+		return err
+	}
+	z.Zone = string(append([]byte(nil), s.String...)) // src can be reused by the driver
+	return nil
+}
+
+// MarshalJSON implements Marshaller interface. ZonedTime is encoded as RFC 3339
+// local time with the zone name suffix, as defined in RFC 9557:
+// `"2020-01-02T10:04:05+01:00[Europe/Warsaw]"`.
+func (z ZonedTime) MarshalJSON() ([]byte, error) {
+	if !z.Valid {
+		return TimeFormatRFC3339Nano.marshalJSON(z.Time), nil
+	}
+	b := []byte{'"'}
+	b = z.Local().AppendFormat(b, time.RFC3339Nano)
+	if z.Zone != "" {
+		b = append(b, '[')
+		b = append(b, z.Zone...)
+		b = append(b, ']')
+	}
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON implements Unmarshaller interface. It accepts the MarshalJSON
+// format and all the Time JSON formats (which are decoded as UTC).
+func (z *ZonedTime) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, nullbytes) {
+		*z = ZonedTime{}
+		return nil
+	}
+	s := strings.Trim(bat.UnsafeByteArrayToStr(data), "\"")
+	var zone string
+	if i := strings.IndexByte(s, '['); i >= 0 {
+		if s[len(s)-1] != ']' {
+			return errors.New("Missing ']' in zoned time")
+		}
+		s, zone = s[:i], s[i+1:len(s)-1]
+		if _, err := loadLocation(zone); err != nil {
+			return fmt.Errorf("Wrong time zone %q: %v", zone, err)
+		}
+	}
+	t, err := parseJSONTime(s, untypedUnixUnit())
+	if err != nil {
+		return err
+	}
+	*z = ZonedTime{t, zone}
+	return nil
+}
+
+// ZonedTimeRecord is a ZonedTime stored in a composite type column:
+//
+//	CREATE TYPE zoned_time AS (ts timestamptz, zone text)
+//
+// Scan and Value encode the timestamp and the zone together. NULL record is
+// represented by an invalid Time.
+type ZonedTimeRecord struct{ ZonedTime }
+
+// Scan implements sql.Scanner interface
+func (z *ZonedTimeRecord) Scan(src interface{}) error {
+	if src == nil {
+		*z = ZonedTimeRecord{}
+		return nil
+	}
+	s, err := bat.UnsafeToString(src)
+	if err != nil {
+		return err
+	}
+	fields, err := ParseRecord(s)
+	if err != nil {
+		return err
+	}
+	if len(fields) != 2 {
+		return fmt.Errorf("Can't scan zoned time record %q: expecting 2 attributes", s)
+	}
+	var res ZonedTime
+	for i, target := range res.ScanTargets() {
+		var v interface{}
+		if fields[i].Valid {
+			v = fields[i].String
+		}
+		if err = target.(sql.Scanner).Scan(v); err != nil {
+			return err
+		}
+	}
+	z.ZonedTime = res
+	return nil
+}
+
+// Value implements sql/driver.Valuer interface
+func (z ZonedTimeRecord) Value() (driver.Value, error) {
+	if !z.Valid {
+		return nil, nil
+	}
+	v, err := z.Time.Value()
+	if err != nil {
+		return nil, err
+	}
+	ts, err := driverValueText(v)
+	if err != nil {
+		return nil, err
+	}
+	return FormatRecord([]String{ts, NewString(z.Zone, true)}), nil
+}
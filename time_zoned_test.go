@@ -0,0 +1,88 @@
+package pgt
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/robert-zaremba/checkers"
+	. "gopkg.in/check.v1"
+)
+
+type ZonedTimeSuite struct{}
+
+func (suite *ZonedTimeSuite) TestZonedTimeJSON(c *C) {
+	z, err := NewZonedTime(time.Date(2020, 1, 2, 9, 4, 5, 0, time.UTC), "Europe/Warsaw")
+	c.Assert(err, IsNil)
+	c.Check(z.Local().Hour(), Equals, 10)
+
+	b, err := json.Marshal(z)
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, `"2020-01-02T10:04:05+01:00[Europe/Warsaw]"`)
+
+	var dest ZonedTime
+	c.Assert(json.Unmarshal(b, &dest), IsNil)
+	c.Check(dest, DeepEquals, z)
+
+	c.Assert(json.Unmarshal([]byte(`"2020-01-02T10:04:05+01:00"`), &dest), IsNil)
+	c.Check(dest.Zone, Equals, "")
+	c.Check(dest.Time, DeepEquals, z.Time)
+
+	testMarshalJSON(ZonedTime{}, &dest, c)
+	c.Check(dest, DeepEquals, ZonedTime{})
+
+	c.Check(json.Unmarshal([]byte(`"2020-01-02T10:04:05+01:00[Mars/Base]"`), &dest), NotNil)
+	_, err = NewZonedTime(time.Now(), "Mars/Base")
+	c.Check(err, NotNil)
+}
+
+func (suite *ZonedTimeSuite) TestZonedTimeScanTargets(c *C) {
+	var z ZonedTime
+	targets := z.ScanTargets()
+	c.Assert(targets, HasLen, 2)
+	c.Assert(targets[0].(interface{ Scan(interface{}) error }).Scan(
+		time.Date(2020, 1, 2, 9, 4, 5, 0, time.UTC)), IsNil)
+	c.Assert(targets[1].(interface{ Scan(interface{}) error }).Scan([]byte("America/New_York")), IsNil)
+	c.Check(z.Zone, Equals, "America/New_York")
+	c.Check(z.Valid, IsTrue)
+	c.Check(z.Local().Hour(), Equals, 4)
+
+	c.Check(targets[1].(interface{ Scan(interface{}) error }).Scan("Mars/Base"), NotNil)
+
+	args := z.Args()
+	c.Assert(args, HasLen, 2)
+	c.Check(args[1], Equals, NewString("America/New_York", false))
+	c.Check(ZonedTime{}.Args()[1], Equals, String{})
+	c.Check(z.Location().String(), Equals, "America/New_York")
+	c.Check(ZonedTime{}.Location(), Equals, time.UTC)
+}
+
+func (suite *ZonedTimeSuite) TestZonedTimeScanValue(c *C) {
+	z, err := NewZonedTime(time.Unix(1577934245, 0), "Europe/Warsaw")
+	c.Assert(err, IsNil)
+	_, err = z.Value()
+	c.Check(err, ErrorMatches, ".*use Args or ZonedTimeRecord")
+	c.Check(z.Scan(time.Now()), ErrorMatches, ".*use ScanTargets or ZonedTimeRecord")
+	c.Check(z.Zone, Equals, "Europe/Warsaw")
+}
+
+func (suite *ZonedTimeSuite) TestZonedTimeRecord(c *C) {
+	var z ZonedTimeRecord
+	c.Assert(z.Scan([]byte(`("2020-01-02 09:04:05+00",Europe/Warsaw)`)), IsNil)
+	c.Check(z.Zone, Equals, "Europe/Warsaw")
+	c.Check(z.Local().Hour(), Equals, 10)
+	v, err := z.Value()
+	c.Check(err, IsNil)
+	c.Check(v, Equals, `("2020-01-02 09:04:05+00",Europe/Warsaw)`)
+
+	c.Assert(z.Scan(`(,)`), IsNil)
+	c.Check(z.ZonedTime, DeepEquals, ZonedTime{})
+
+	c.Check(z.Scan(`(infinity,)`), NotNil)
+	c.Check(z.Scan(`("2020-01-02 09:04:05+00",Mars/Olympus)`), NotNil)
+	c.Check(z.Scan(`(1)`), NotNil)
+	c.Assert(z.Scan(nil), IsNil)
+	c.Check(z.Valid, IsFalse)
+	v, err = z.Value()
+	c.Check(err, IsNil)
+	c.Check(v, IsNil)
+}
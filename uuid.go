@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"database/sql/driver"
 	"errors"
-	"unsafe"
+	"fmt"
 
 	"github.com/pborman/uuid"
 	"github.com/robert-zaremba/errstack"
@@ -14,20 +14,90 @@ import (
 // UUID type to wrap uuid package
 type UUID uuid.UUID
 
-// Scan is the scanner for UUID
+// Scan is the scanner for UUID. It accepts NULL, canonical text representation
+// (`xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx`) and 16 bytes binary form.
 func (u *UUID) Scan(value interface{}) error {
 	if value == nil {
-		u = nil
+		*u = nil
+		return nil
+	}
+	if bs, ok := value.([]byte); ok && len(bs) == 16 {
+		*u = append(UUID(nil), bs...)
 		return nil
 	}
 	str, err := bat.UnsafeToString(value)
 	if err != nil {
 		return err
 	}
-	*u = UUID(uuid.Parse(str))
+	parsed, err := parseCanonicalUUID(str)
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
+
+// parseCanonicalUUID parses UUID in the canonical form produced by Postgresql.
+func parseCanonicalUUID(s string) (UUID, error) {
+	u := make(UUID, 16)
+	if err := decodeCanonicalUUID(u, s); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// decodeCanonicalUUID decodes UUID in the canonical form into 16 bytes dst.
+func decodeCanonicalUUID(dst []byte, s string) error {
+	if len(s) != 36 {
+		return fmt.Errorf("Invalid UUID %q: expected 36 characters, got %d", s, len(s))
+	}
+	j := 0
+	for i := 0; i < 36; i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return fmt.Errorf("Invalid UUID %q: expected '-' at position %d", s, i)
+			}
+			continue
+		}
+		hi, ok1 := fromHexChar(s[i])
+		lo, ok2 := fromHexChar(s[i+1])
+		if !ok1 || !ok2 {
+			return fmt.Errorf("Invalid UUID %q: non hexadecimal character at position %d", s, i)
+		}
+		dst[j] = hi<<4 | lo
+		j++
+		i++
+	}
 	return nil
 }
 
+const hexDigits = "0123456789abcdef"
+
+// appendUUID appends canonical text representation of 16 bytes u to b
+func appendUUID(b []byte, u []byte) []byte {
+	for i, x := range u {
+		switch i {
+		case 4, 6, 8, 10:
+			b = append(b, '-')
+		}
+		b = append(b, hexDigits[x>>4], hexDigits[x&0x0f])
+	}
+	return b
+}
+
+func fromHexChar(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
 // Value is the valuer for UUID
 func (u UUID) Value() (driver.Value, error) {
 	if u.Empty() {
@@ -41,8 +111,11 @@ func (u UUID) Empty() bool {
 	return u == nil
 }
 
-// MarshalJSON implements Marshaller interface
+// MarshalJSON implements Marshaller interface. Empty UUID is encoded as null.
 func (u UUID) MarshalJSON() ([]byte, error) {
+	if u.Empty() {
+		return nullbytes, nil
+	}
 	bs, err := uuid.UUID(u).MarshalText()
 	if err != nil {
 		return bs, err
@@ -54,33 +127,46 @@ func (u UUID) MarshalJSON() ([]byte, error) {
 	return bsStr, nil
 }
 
-// UnmarshalJSON implements Unmarshaller interface
+// UnmarshalJSON implements Unmarshaller interface. null is decoded as an empty UUID.
+// Strings are parsed as in ParseUUID.
 func (u *UUID) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, nullbytes) {
+		*u = nil
+		return nil
+	}
 	// firstly we need to drop `"`
-	if len(data) < 2 {
-		return errors.New("expecting string data (value encoled in \"\")")
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return errors.New("expecting string data (value encoled in \"\") or null")
 	}
-	var uu uuid.UUID
-	err := uu.UnmarshalText(data[1 : len(data)-1])
-	if err == nil {
-		*u = *(*UUID)(unsafe.Pointer(&uu))
+	parsed, err := parseCanonicalUUID(bat.UnsafeByteArrayToStr(data[1 : len(data)-1]))
+	if err != nil {
+		return err
 	}
-	return err
+	*u = parsed
+	return nil
 }
 
-// MarshalYAML implements Marshaler interface of YAML
+// MarshalYAML implements Marshaler interface of YAML. Empty UUID is encoded as null.
 func (u UUID) MarshalYAML() (interface{}, error) {
-	return u.MarshalJSON()
+	if u.Empty() {
+		return nil, nil
+	}
+	return u.String(), nil
 }
 
-// UnmarshalYAML implements Unmarshaler interface of YAML
+// UnmarshalYAML implements Unmarshaler interface of YAML. null and empty string
+// are decoded as an empty UUID.
 func (u *UUID) UnmarshalYAML(unmarshaler func(interface{}) error) error {
-	var s string
+	var s *string
 	err := unmarshaler(&s)
 	if err != nil {
 		return err
 	}
-	*u, err = ParseUUID(s)
+	if s == nil || *s == "" {
+		*u = nil
+		return nil
+	}
+	*u, err = ParseUUID(*s)
 	return err
 }
 
@@ -94,11 +180,12 @@ func (u UUID) Equals(u2 UUID) bool {
 	return bytes.Equal(u, u2)
 }
 
-// ParseUUID parses string into UUID value
+// ParseUUID parses string into UUID value. Only the canonical form
+// (`xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx`) is accepted.
 func ParseUUID(s string) (UUID, errstack.E) {
-	u := UUID(uuid.Parse(s))
-	if u == nil {
-		return u, errstack.NewReqF("Failed to parse %s as UUID", s)
+	u, err := parseCanonicalUUID(s)
+	if err != nil {
+		return nil, errstack.WrapAsReq(err, "Failed to parse UUID")
 	}
 	return u, nil
 }
@@ -119,10 +206,7 @@ func RandomUUID() UUID {
 }
 
 // UUIDIterator is an interface used by `ExtractUUIDs` function
-type UUIDIterator interface {
-	Get() UUID
-	Next() bool
-}
+type UUIDIterator = Iterator[UUID]
 
 // ExtractUUIDs returns a list of unique ids from given interator
 func ExtractUUIDs(i UUIDIterator, optLength ...int) []UUID {
@@ -130,24 +214,30 @@ func ExtractUUIDs(i UUIDIterator, optLength ...int) []UUID {
 	if len(optLength) > 0 {
 		length = optLength[0]
 	}
-	set := make(map[string]bool, length)
-	res := []UUID{}
-	for i.Next() {
-		v := i.Get()
-		s := v.String()
-		if !set[s] {
-			set[s] = true
-			res = append(res, v)
-		}
-	}
-	return res
+	return uniqueFunc(Seq(i), UUID.Fixed, length)
 }
 
-// UUIDs is a slice of UUID
+// UUIDs is a slice of UUID. It represents Postgresql `uuid[]` without NULL
+// elements, use NullUUIDs if the array can contain NULLs.
+// Scanned elements share one FixedUUIDs array, so scanning doesn't allocate per
+// element.
 type UUIDs []UUID
 
+// Fixed converts ls to FixedUUIDs. Empty UUIDs are converted to nil UUIDs.
+func (ls UUIDs) Fixed() FixedUUIDs {
+	res := make(FixedUUIDs, len(ls))
+	for i := range ls {
+		res[i] = ls[i].Fixed()
+	}
+	return res
+}
+
 // Scan implements sql Scanner interface
 func (ls *UUIDs) Scan(src interface{}) error {
+	if src == nil {
+		*ls = nil
+		return nil
+	}
 	bs, err := bat.UnsafeToBytes(src)
 	if err != nil {
 		return err
@@ -156,40 +246,83 @@ func (ls *UUIDs) Scan(src interface{}) error {
 	return err
 }
 
-// Value implements sql Valuer interface
+// Value implements sql Valuer interface. It returns error if any of the elements
+// is empty.
 func (ls UUIDs) Value() (driver.Value, error) {
+	if ls == nil && NilArrayAsNull() {
+		return nil, nil
+	}
+	for i := range ls {
+		if ls[i].Empty() {
+			return nil, fmt.Errorf("Can't encode UUID array: empty element at index %d", i)
+		}
+	}
+	return NullUUIDs(ls).Value()
+}
+
+// NullUUIDs is a slice of UUID for Postgresql `uuid[]` with NULL elements, which
+// are represented by empty UUIDs.
+type NullUUIDs []UUID
+
+// Scan implements sql Scanner interface
+func (ls *NullUUIDs) Scan(src interface{}) error {
+	if src == nil {
+		*ls = nil
+		return nil
+	}
+	bs, err := bat.UnsafeToBytes(src)
+	if err != nil {
+		return err
+	}
+	*ls, err = parseUUIDArray(bs, true)
+	return err
+}
+
+// Value implements sql Valuer interface
+func (ls NullUUIDs) Value() (driver.Value, error) {
+	if ls == nil && NilArrayAsNull() {
+		return nil, nil
+	}
 	length := 2 // for {}
 	if len(ls) > 0 {
 		length += 37*len(ls) - 1 // = 36*len(ls) + len(ls)-1;; 36 = uuid str len, len(ls)-1 = amount of ','
 	}
-	out := make([]byte, length)
-	out[0] = '{'
-	i := 1
-	for _, id := range ls {
-		for j, s := 0, id.String(); j < len(s); j++ {
-			out[i] = s[j]
-			i++
+	out := make([]byte, 0, length)
+	out = append(out, '{')
+	for i, id := range ls {
+		if i > 0 {
+			out = append(out, ',')
+		}
+		if id.Empty() {
+			out = append(out, "NULL"...)
+		} else {
+			out = appendUUID(out, id)
 		}
-		out[i] = ','
-		i++
 	}
-	out[length-1] = '}' // if len(ls) >0 this will overwrite the final ','
-
+	out = append(out, '}')
 	return bat.UnsafeByteArrayToStr(out), nil
 }
 
-// ParseUUIDArray parses UUID array
+// ParseUUIDArray parses UUID array. Elements must be in the canonical form,
+// NULL elements are not allowed.
 func ParseUUIDArray(src []byte) (UUIDs, error) {
-	if bytes.Equal(src, EmptyArray) {
-		return UUIDs{}, nil
-	}
-	vals := SplitSimpleArray(src)
-	var results = make(UUIDs, len(vals))
-	var err errstack.E
-	for i := range vals {
-		if results[i], err = ParseUUID(bat.UnsafeByteArrayToStr(vals[i])); err != nil {
-			return nil, err
-		}
+	return parseUUIDArray(src, false)
+}
+
+// parseUUIDArray parses UUID array into UUIDs backed by one FixedUUIDs array
+func parseUUIDArray(src []byte, allowNull bool) ([]UUID, error) {
+	var nulls []int
+	var null func(int)
+	if allowNull {
+		null = func(i int) { nulls = append(nulls, i) }
+	}
+	ids, err := parseFixedUUIDArray(src, null)
+	if err != nil {
+		return nil, err
+	}
+	res := ids.UUIDs()
+	for _, i := range nulls {
+		res[i] = nil
 	}
-	return results, nil
+	return res, nil
 }
@@ -0,0 +1,279 @@
+package pgt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	bat "github.com/robert-zaremba/go-bat"
+)
+
+const (
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	base32Alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ" // Crockford
+)
+
+var (
+	base58Index = makeAlphabetIndex(base58Alphabet)
+	base32Index = makeAlphabetIndex(base32Alphabet)
+)
+
+func makeAlphabetIndex(alphabet string) [256]int8 {
+	var idx [256]int8
+	for i := range idx {
+		idx[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		idx[alphabet[i]] = int8(i)
+	}
+	return idx
+}
+
+func init() {
+	// Crockford base32 decoding is case insensitive and maps similar looking letters
+	for i := 0; i < len(base32Alphabet); i++ {
+		c := base32Alphabet[i]
+		if isLetter(c) {
+			base32Index[c+'a'-'A'] = int8(i)
+		}
+	}
+	base32Index['O'], base32Index['o'] = 0, 0
+	base32Index['I'], base32Index['i'] = 1, 1
+	base32Index['L'], base32Index['l'] = 1, 1
+}
+
+// Base58 encodes u using the Bitcoin base58 alphabet. The result has at most 22
+// characters.
+func (u FixedUUID) Base58() string {
+	num := u // big endian number, divided in place
+	var out [22]byte
+	i := len(out)
+	start := 0
+	for start < len(num) && num[start] == 0 {
+		start++
+	}
+	zeros := start
+	for start < len(num) {
+		var rem int
+		for j := start; j < len(num); j++ {
+			x := rem<<8 | int(num[j])
+			num[j] = byte(x / 58)
+			rem = x % 58
+		}
+		i--
+		out[i] = base58Alphabet[rem]
+		for start < len(num) && num[start] == 0 {
+			start++
+		}
+	}
+	for ; zeros > 0; zeros-- {
+		i--
+		out[i] = base58Alphabet[0]
+	}
+	return string(out[i:])
+}
+
+// ParseUUIDBase58 decodes UUID encoded with FixedUUID.Base58
+func ParseUUIDBase58(s string) (FixedUUID, error) {
+	var u FixedUUID
+	if s == "" || len(s) > 22 {
+		return u, fmt.Errorf("Invalid base58 UUID %q: wrong length", s)
+	}
+	for i := 0; i < len(s); i++ {
+		carry := int(base58Index[s[i]])
+		if carry < 0 {
+			return u, fmt.Errorf("Invalid base58 UUID %q: wrong character at position %d", s, i)
+		}
+		for j := len(u) - 1; j >= 0; j-- {
+			x := int(u[j])*58 + carry
+			u[j] = byte(x)
+			carry = x >> 8
+		}
+		if carry != 0 {
+			return u, fmt.Errorf("Invalid base58 UUID %q: value out of range", s)
+		}
+	}
+	return u, nil
+}
+
+// Base32 encodes u using Crockford base32, in the same way as ULID. The result
+// has 26 characters.
+func (u FixedUUID) Base32() string {
+	var out [26]byte
+	// 128 bits are encoded in 26 * 5 = 130 bits, from the least significant
+	var acc uint
+	var bits uint
+	j := len(u) - 1
+	for i := len(out) - 1; i >= 0; i-- {
+		for bits < 5 && j >= 0 {
+			acc |= uint(u[j]) << bits
+			bits += 8
+			j--
+		}
+		out[i] = base32Alphabet[acc&0x1f]
+		acc >>= 5
+		bits -= 5
+	}
+	return string(out[:])
+}
+
+// ParseUUIDBase32 decodes UUID (or ULID) encoded with Crockford base32. Decoding
+// is case insensitive.
+func ParseUUIDBase32(s string) (FixedUUID, error) {
+	var u FixedUUID
+	if len(s) != 26 {
+		return u, fmt.Errorf("Invalid base32 UUID %q: expected 26 characters", s)
+	}
+	if base32Index[s[0]] > 7 {
+		return u, fmt.Errorf("Invalid base32 UUID %q: value out of range", s)
+	}
+	var acc uint
+	var bits uint
+	j := len(u) - 1
+	for i := len(s) - 1; i >= 0; i-- {
+		x := base32Index[s[i]]
+		if x < 0 {
+			return u, fmt.Errorf("Invalid base32 UUID %q: wrong character at position %d", s, i)
+		}
+		acc |= uint(x) << bits
+		bits += 5
+		if bits >= 8 && j >= 0 {
+			u[j] = byte(acc)
+			acc >>= 8
+			bits -= 8
+			j--
+		}
+	}
+	return u, nil
+}
+
+// Base64 encodes u using URL safe base64 encoding without padding. The result
+// has 22 characters.
+func (u FixedUUID) Base64() string {
+	return base64.RawURLEncoding.EncodeToString(u[:])
+}
+
+// ParseUUIDBase64 decodes UUID encoded with FixedUUID.Base64
+func ParseUUIDBase64(s string) (FixedUUID, error) {
+	var u FixedUUID
+	if len(s) != 22 {
+		return u, fmt.Errorf("Invalid base64 UUID %q: expected 22 characters", s)
+	}
+	if _, err := base64.RawURLEncoding.Decode(u[:], bat.UnsafeStrToByteArray(s)); err != nil {
+		return u, fmt.Errorf("Invalid base64 UUID %q: %v", s, err)
+	}
+	return u, nil
+}
+
+// uuidCodec defines text encoding used by the UUID sibling types
+type uuidCodec struct {
+	encode func(FixedUUID) string
+	decode func(string) (FixedUUID, error)
+}
+
+var (
+	base58Codec = uuidCodec{FixedUUID.Base58, ParseUUIDBase58}
+	base32Codec = uuidCodec{FixedUUID.Base32, ParseUUIDBase32}
+	base64Codec = uuidCodec{FixedUUID.Base64, ParseUUIDBase64}
+)
+
+// parse decodes s with the codec. Canonical UUID form is accepted as well.
+func (c uuidCodec) parse(s string) (FixedUUID, error) {
+	if len(s) == 36 && strings.Count(s, "-") == 4 {
+		return ParseFixedUUID(s)
+	}
+	return c.decode(s)
+}
+
+func (c uuidCodec) marshalJSON(u FixedUUID) []byte {
+	return []byte(`"` + c.encode(u) + `"`)
+}
+
+// unmarshalJSON decodes data into u. null leaves u unchanged.
+func (c uuidCodec) unmarshalJSON(u *FixedUUID, data []byte) (err error) {
+	if bytes.Equal(data, nullbytes) {
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return errors.New("expecting string data (value encoled in \"\") or null")
+	}
+	*u, err = c.parse(string(data[1 : len(data)-1]))
+	return err
+}
+
+// UUIDBase58 is a FixedUUID which text and JSON representation uses base58
+// encoding. Scan and Value use the native Postgresql `uuid` format.
+// Unmarshalling accepts the canonical UUID form as well.
+type UUIDBase58 struct{ FixedUUID }
+
+// String returns base58 representation of the UUID
+func (u UUIDBase58) String() string { return base58Codec.encode(u.FixedUUID) }
+
+// MarshalText implements encoding.TextMarshaler interface
+func (u UUIDBase58) MarshalText() ([]byte, error) { return []byte(u.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler interface
+func (u *UUIDBase58) UnmarshalText(data []byte) (err error) {
+	u.FixedUUID, err = base58Codec.parse(string(data))
+	return err
+}
+
+// MarshalJSON implements Marshaller interface
+func (u UUIDBase58) MarshalJSON() ([]byte, error) { return base58Codec.marshalJSON(u.FixedUUID), nil }
+
+// UnmarshalJSON implements Unmarshaller interface
+func (u *UUIDBase58) UnmarshalJSON(data []byte) error {
+	return base58Codec.unmarshalJSON(&u.FixedUUID, data)
+}
+
+// UUIDBase32 is a FixedUUID which text and JSON representation uses Crockford
+// base32 encoding (ULID compatible). Scan and Value use the native Postgresql
+// `uuid` format. Unmarshalling accepts the canonical UUID form as well.
+type UUIDBase32 struct{ FixedUUID }
+
+// String returns base32 representation of the UUID
+func (u UUIDBase32) String() string { return base32Codec.encode(u.FixedUUID) }
+
+// MarshalText implements encoding.TextMarshaler interface
+func (u UUIDBase32) MarshalText() ([]byte, error) { return []byte(u.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler interface
+func (u *UUIDBase32) UnmarshalText(data []byte) (err error) {
+	u.FixedUUID, err = base32Codec.parse(string(data))
+	return err
+}
+
+// MarshalJSON implements Marshaller interface
+func (u UUIDBase32) MarshalJSON() ([]byte, error) { return base32Codec.marshalJSON(u.FixedUUID), nil }
+
+// UnmarshalJSON implements Unmarshaller interface
+func (u *UUIDBase32) UnmarshalJSON(data []byte) error {
+	return base32Codec.unmarshalJSON(&u.FixedUUID, data)
+}
+
+// UUIDBase64 is a FixedUUID which text and JSON representation uses URL safe
+// base64 encoding. Scan and Value use the native Postgresql `uuid` format.
+// Unmarshalling accepts the canonical UUID form as well.
+type UUIDBase64 struct{ FixedUUID }
+
+// String returns base64 representation of the UUID
+func (u UUIDBase64) String() string { return base64Codec.encode(u.FixedUUID) }
+
+// MarshalText implements encoding.TextMarshaler interface
+func (u UUIDBase64) MarshalText() ([]byte, error) { return []byte(u.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler interface
+func (u *UUIDBase64) UnmarshalText(data []byte) (err error) {
+	u.FixedUUID, err = base64Codec.parse(string(data))
+	return err
+}
+
+// MarshalJSON implements Marshaller interface
+func (u UUIDBase64) MarshalJSON() ([]byte, error) { return base64Codec.marshalJSON(u.FixedUUID), nil }
+
+// UnmarshalJSON implements Unmarshaller interface
+func (u *UUIDBase64) UnmarshalJSON(data []byte) error {
+	return base64Codec.unmarshalJSON(&u.FixedUUID, data)
+}
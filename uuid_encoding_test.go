@@ -0,0 +1,96 @@
+package pgt
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+type UUIDEncodingSuite struct{}
+
+func (suite *UUIDEncodingSuite) TestKnownValues(c *C) {
+	u, err := ParseFixedUUID("01563df6-b5d3-d676-4c61-efb99302bd5b")
+	c.Assert(err, IsNil)
+	c.Check(u.Base32(), Equals, "01ARYZDDEKTSV4RRFFQ69G5FAV")
+	c.Check(u.Base58(), Equals, "AaLsZ32fZV9cTKC219rg2")
+	c.Check(u.Base64(), Equals, "AVY99rXT1nZMYe-5kwK9Ww")
+
+	one, err := ParseFixedUUID("00000000-0000-0000-0000-000000000001")
+	c.Assert(err, IsNil)
+	c.Check(one.Base58(), Equals, "1111111111111112")
+	c.Check(FixedUUID{}.Base58(), Equals, "1111111111111111")
+
+	d, err := ParseUUIDBase32("01aryzddektsv4rrffq69g5fav")
+	c.Assert(err, IsNil)
+	c.Check(d, Equals, u)
+	d, err = ParseUUIDBase32("O1ARYZDDEKTSV4RRFFQ69G5FAV")
+	c.Assert(err, IsNil)
+	c.Check(d, Equals, u)
+}
+
+func (suite *UUIDEncodingSuite) TestRoundTrip(c *C) {
+	var max FixedUUID
+	for i := range max {
+		max[i] = 0xff
+	}
+	ids := []FixedUUID{{}, max, {1}, RandomUUID().Fixed(), NewUUIDv7().Fixed()}
+	for _, id := range ids {
+		comment := Commentf("%s", id.UUID())
+		u, err := ParseUUIDBase58(id.Base58())
+		c.Assert(err, IsNil, comment)
+		c.Check(u, Equals, id, comment)
+		u, err = ParseUUIDBase32(id.Base32())
+		c.Assert(err, IsNil, comment)
+		c.Check(u, Equals, id, comment)
+		u, err = ParseUUIDBase64(id.Base64())
+		c.Assert(err, IsNil, comment)
+		c.Check(u, Equals, id, comment)
+	}
+	c.Check(max.Base32(), Equals, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ")
+}
+
+func (suite *UUIDEncodingSuite) TestInvalid(c *C) {
+	for _, s := range []string{"", "0OIl", "zzzzzzzzzzzzzzzzzzzzzzz", "zzzzzzzzzzzzzzzzzzzzzz"} {
+		_, err := ParseUUIDBase58(s)
+		c.Check(err, NotNil, Commentf("%s", s))
+	}
+	for _, s := range []string{"", "8ZZZZZZZZZZZZZZZZZZZZZZZZZ", "0ZZZZZZZZZZZZZZZZZZZZZZZZU"} {
+		_, err := ParseUUIDBase32(s)
+		c.Check(err, NotNil, Commentf("%s", s))
+	}
+	for _, s := range []string{"", "AVY99rXT1nZMYe+5kwK9Ww"} {
+		_, err := ParseUUIDBase64(s)
+		c.Check(err, NotNil, Commentf("%s", s))
+	}
+}
+
+func (suite *UUIDEncodingSuite) TestSiblingTypes(c *C) {
+	id := RandomUUID().Fixed()
+	type ids struct {
+		A UUIDBase58
+		B UUIDBase32
+		C UUIDBase64
+		N UUIDBase58
+	}
+	obj := ids{UUIDBase58{id}, UUIDBase32{id}, UUIDBase64{id}, UUIDBase58{}}
+	b, err := json.Marshal(obj)
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, `{"A":"`+id.Base58()+`","B":"`+id.Base32()+
+		`","C":"`+id.Base64()+`","N":"1111111111111111"}`)
+	var dest ids
+	c.Assert(json.Unmarshal(b, &dest), IsNil)
+	c.Check(dest, Equals, obj)
+
+	c.Assert(json.Unmarshal([]byte(`{"A":"`+id.String()+`"}`), &dest), IsNil)
+	c.Check(dest.A.FixedUUID, Equals, id)
+
+	v, err := obj.A.Value()
+	c.Assert(err, IsNil)
+	c.Check(v, Equals, id.String())
+	c.Assert(dest.B.Scan(id.String()), IsNil)
+	c.Check(dest.B.FixedUUID, Equals, id)
+
+	var m map[UUIDBase64]int
+	c.Assert(json.Unmarshal([]byte(`{"`+id.Base64()+`":1}`), &m), IsNil)
+	c.Check(m[UUIDBase64{id}], Equals, 1)
+}
@@ -0,0 +1,288 @@
+package pgt
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	bat "github.com/robert-zaremba/go-bat"
+)
+
+// FixedUUID is a UUID value stored in an array, so it can be used as a map key.
+// Scan, UnmarshalText, UnmarshalJSON and AppendText don't allocate. Value,
+// MarshalText and MarshalJSON allocate the returned text.
+// FixedUUID is never NULL: the zero value is the nil UUID
+// (`00000000-0000-0000-0000-000000000000`), which is a proper `uuid` value. Use
+// NullFixedUUID for nullable columns.
+type FixedUUID [16]byte
+
+// Fixed converts u to FixedUUID. Empty UUID (NULL) is converted to the nil UUID,
+// use NullFixed to preserve NULL.
+func (u UUID) Fixed() FixedUUID {
+	var f FixedUUID
+	copy(f[:], u)
+	return f
+}
+
+// NullFixed converts u to NullFixedUUID. Empty UUID is converted to NULL.
+func (u UUID) NullFixed() NullFixedUUID {
+	return NullFixedUUID{UUID: u.Fixed(), Valid: !u.Empty()}
+}
+
+// UUID converts u to UUID
+func (u FixedUUID) UUID() UUID {
+	return UUID(u[:])
+}
+
+// ParseFixedUUID parses UUID in the canonical form
+// (`xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx`).
+func ParseFixedUUID(s string) (FixedUUID, error) {
+	var u FixedUUID
+	err := decodeCanonicalUUID(u[:], s)
+	return u, err
+}
+
+// IsZero checks if u is the nil UUID
+func (u FixedUUID) IsZero() bool {
+	return u == FixedUUID{}
+}
+
+// String converts UUID into string
+func (u FixedUUID) String() string {
+	return string(u.AppendText(make([]byte, 0, 36)))
+}
+
+// AppendText appends the canonical UUID text representation to b
+func (u FixedUUID) AppendText(b []byte) []byte {
+	return appendUUID(b, u[:])
+}
+
+// Scan implements sql.Scanner interface. It accepts canonical text
+// representation and 16 bytes binary form. It doesn't allocate. NULL can't be
+// scanned, use NullFixedUUID for nullable columns.
+func (u *FixedUUID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		return errors.New("Can't scan NULL into FixedUUID, use NullFixedUUID")
+	case []byte:
+		if len(v) == 16 {
+			copy(u[:], v)
+			return nil
+		}
+	}
+	s, err := bat.UnsafeToString(src)
+	if err != nil {
+		return err
+	}
+	return decodeCanonicalUUID(u[:], s)
+}
+
+// Value implements sql/driver.Valuer interface. It allocates the returned
+// string, as driver.Value can't reference u.
+func (u FixedUUID) Value() (driver.Value, error) {
+	return u.String(), nil
+}
+
+// MarshalText implements encoding.TextMarshaler interface
+func (u FixedUUID) MarshalText() ([]byte, error) {
+	return u.AppendText(make([]byte, 0, 36)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler interface. It doesn't
+// allocate.
+func (u *FixedUUID) UnmarshalText(data []byte) error {
+	return decodeCanonicalUUID(u[:], bat.UnsafeByteArrayToStr(data))
+}
+
+// MarshalJSON implements Marshaller interface
+func (u FixedUUID) MarshalJSON() ([]byte, error) {
+	b := make([]byte, 1, 38)
+	b[0] = '"'
+	b = u.AppendText(b)
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON implements Unmarshaller interface. It doesn't allocate. As
+// for other non nullable types, null leaves u unchanged.
+func (u *FixedUUID) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, nullbytes) {
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return errors.New("expecting string data (value encoled in \"\") or null")
+	}
+	return decodeCanonicalUUID(u[:], bat.UnsafeByteArrayToStr(data[1:len(data)-1]))
+}
+
+// NullFixedUUID is a nullable FixedUUID. It is NULL when Valid is false.
+type NullFixedUUID struct {
+	UUID  FixedUUID
+	Valid bool
+}
+
+// Scan implements sql.Scanner interface
+func (u *NullFixedUUID) Scan(src interface{}) error {
+	if src == nil {
+		*u = NullFixedUUID{}
+		return nil
+	}
+	if err := u.UUID.Scan(src); err != nil {
+		return err
+	}
+	u.Valid = true
+	return nil
+}
+
+// Value implements sql/driver.Valuer interface
+func (u NullFixedUUID) Value() (driver.Value, error) {
+	if !u.Valid {
+		return nil, nil
+	}
+	return u.UUID.String(), nil
+}
+
+// MarshalJSON implements Marshaller interface. NULL is encoded as null.
+func (u NullFixedUUID) MarshalJSON() ([]byte, error) {
+	if !u.Valid {
+		return nullbytes, nil
+	}
+	return u.UUID.MarshalJSON()
+}
+
+// UnmarshalJSON implements Unmarshaller interface. null is decoded as NULL.
+func (u *NullFixedUUID) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, nullbytes) {
+		*u = NullFixedUUID{}
+		return nil
+	}
+	if err := u.UUID.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	u.Valid = true
+	return nil
+}
+
+// FixedUUIDs is a slice of FixedUUID for Postgresql `uuid[]` type without NULL
+// elements, use NullFixedUUIDs if the array can contain NULLs.
+type FixedUUIDs []FixedUUID
+
+// UUIDs returns ls as UUIDs. The elements share memory with ls.
+func (ls FixedUUIDs) UUIDs() UUIDs {
+	res := make(UUIDs, len(ls))
+	for i := range ls {
+		res[i] = ls[i][:]
+	}
+	return res
+}
+
+// Scan implements sql.Scanner interface
+func (ls *FixedUUIDs) Scan(src interface{}) error {
+	if src == nil {
+		*ls = nil
+		return nil
+	}
+	bs, err := bat.UnsafeToBytes(src)
+	if err != nil {
+		return err
+	}
+	res, err := parseFixedUUIDArray(bs, nil)
+	if err != nil {
+		return err
+	}
+	*ls = res
+	return nil
+}
+
+// Value implements sql/driver.Valuer interface
+func (ls FixedUUIDs) Value() (driver.Value, error) {
+	if ls == nil && NilArrayAsNull() {
+		return nil, nil
+	}
+	out := make([]byte, 0, 2+37*len(ls))
+	out = append(out, '{')
+	for i, id := range ls {
+		if i > 0 {
+			out = append(out, ',')
+		}
+		out = id.AppendText(out)
+	}
+	out = append(out, '}')
+	return bat.UnsafeByteArrayToStr(out), nil
+}
+
+// NullFixedUUIDs is a slice of NullFixedUUID for Postgresql `uuid[]` type with
+// NULL elements.
+type NullFixedUUIDs []NullFixedUUID
+
+// Scan implements sql.Scanner interface
+func (ls *NullFixedUUIDs) Scan(src interface{}) error {
+	if src == nil {
+		*ls = nil
+		return nil
+	}
+	bs, err := bat.UnsafeToBytes(src)
+	if err != nil {
+		return err
+	}
+	var nulls []int
+	ids, err := parseFixedUUIDArray(bs, func(i int) { nulls = append(nulls, i) })
+	if err != nil {
+		return err
+	}
+	res := make(NullFixedUUIDs, len(ids))
+	for i := range ids {
+		res[i] = NullFixedUUID{UUID: ids[i], Valid: true}
+	}
+	for _, i := range nulls {
+		res[i] = NullFixedUUID{}
+	}
+	*ls = res
+	return nil
+}
+
+// Value implements sql/driver.Valuer interface
+func (ls NullFixedUUIDs) Value() (driver.Value, error) {
+	if ls == nil && NilArrayAsNull() {
+		return nil, nil
+	}
+	out := make([]byte, 0, 2+37*len(ls))
+	out = append(out, '{')
+	for i, id := range ls {
+		if i > 0 {
+			out = append(out, ',')
+		}
+		if id.Valid {
+			out = id.UUID.AppendText(out)
+		} else {
+			out = append(out, "NULL"...)
+		}
+	}
+	out = append(out, '}')
+	return bat.UnsafeByteArrayToStr(out), nil
+}
+
+// parseFixedUUIDArray parses `uuid[]` text representation. null is called with
+// the index of each NULL element, which is left as the nil UUID. NULL elements
+// are rejected if null is nil.
+func parseFixedUUIDArray(src []byte, null func(i int)) (FixedUUIDs, error) {
+	if bytes.Equal(src, EmptyArray) {
+		return FixedUUIDs{}, nil
+	}
+	vals := SplitSimpleArray(src)
+	res := make(FixedUUIDs, len(vals))
+	for i, v := range vals {
+		s := bat.UnsafeByteArrayToStr(v)
+		if s == "NULL" {
+			if null == nil {
+				return nil, fmt.Errorf("Can't parse UUID array: NULL element at index %d", i)
+			}
+			null(i)
+			continue
+		}
+		if err := decodeCanonicalUUID(res[i][:], s); err != nil {
+			return nil, fmt.Errorf("Can't parse UUID array element %d: %v", i, err)
+		}
+	}
+	return res, nil
+}
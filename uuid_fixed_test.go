@@ -0,0 +1,168 @@
+package pgt
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	. "github.com/robert-zaremba/checkers"
+	. "gopkg.in/check.v1"
+)
+
+type FixedUUIDSuite struct{}
+
+const nilUUID = "00000000-0000-0000-0000-000000000000"
+
+func (suite *FixedUUIDSuite) TestConversions(c *C) {
+	id := RandomUUID()
+	f := id.Fixed()
+	c.Check(f.String(), Equals, id.String())
+	c.Check(f.UUID(), SliceEquals, id)
+	c.Check(UUID(nil).Fixed().IsZero(), IsTrue)
+	c.Check(FixedUUID{}.UUID().String(), Equals, nilUUID)
+	c.Check(id.NullFixed(), Equals, NullFixedUUID{f, true})
+	c.Check(UUID(nil).NullFixed(), Equals, NullFixedUUID{})
+
+	parsed, err := ParseFixedUUID(id.String())
+	c.Assert(err, IsNil)
+	c.Check(parsed, Equals, f)
+	_, err = ParseFixedUUID("abc")
+	c.Check(err, NotNil)
+
+	m := map[FixedUUID]int{f: 1}
+	c.Check(m[id.Fixed()], Equals, 1)
+}
+
+func (suite *FixedUUIDSuite) TestScanValueJSON(c *C) {
+	id := RandomUUID().Fixed()
+	var u FixedUUID
+	c.Assert(u.Scan([]byte(id.String())), IsNil)
+	c.Check(u, Equals, id)
+	c.Assert(u.Scan(id[:]), IsNil)
+	c.Check(u, Equals, id)
+	v, err := u.Value()
+	c.Assert(err, IsNil)
+	c.Check(v, Equals, id.String())
+	c.Check(u.Scan(nil), NotNil)
+
+	// the nil UUID is a value, not NULL
+	var zero FixedUUID
+	c.Check(mustValue(c, zero), Equals, nilUUID)
+	c.Assert(u.Scan(nilUUID), IsNil)
+	c.Check(u.IsZero(), IsTrue)
+	text, err := zero.MarshalText()
+	c.Assert(err, IsNil)
+	c.Check(string(text), Equals, nilUUID)
+	c.Check(u.UnmarshalText(nil), NotNil)
+
+	type composed struct {
+		ID   FixedUUID
+		Zero FixedUUID
+	}
+	obj := composed{ID: id}
+	b, err := json.Marshal(obj)
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, `{"ID":"`+id.String()+`","Zero":"`+nilUUID+`"}`)
+	var dest composed
+	c.Assert(json.Unmarshal(b, &dest), IsNil)
+	c.Check(dest, Equals, obj)
+	c.Assert(json.Unmarshal([]byte(`{"ID":null}`), &dest), IsNil)
+	c.Check(dest.ID, Equals, id)
+}
+
+func (suite *FixedUUIDSuite) TestNullFixedUUID(c *C) {
+	id := RandomUUID().Fixed()
+	var u NullFixedUUID
+	c.Assert(u.Scan(id.String()), IsNil)
+	c.Check(u, Equals, NullFixedUUID{id, true})
+	c.Check(mustValue(c, u), Equals, id.String())
+	c.Assert(u.Scan(nil), IsNil)
+	c.Check(u, Equals, NullFixedUUID{})
+	c.Check(mustValue(c, u), IsNil)
+	c.Assert(u.Scan(nilUUID), IsNil)
+	c.Check(u, Equals, NullFixedUUID{Valid: true})
+	c.Check(mustValue(c, u), Equals, nilUUID)
+
+	type composed struct {
+		ID, Zero, Null NullFixedUUID
+	}
+	obj := composed{ID: NullFixedUUID{id, true}, Zero: NullFixedUUID{Valid: true}}
+	b, err := json.Marshal(obj)
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, `{"ID":"`+id.String()+`","Zero":"`+nilUUID+`","Null":null}`)
+	var dest composed
+	c.Assert(json.Unmarshal(b, &dest), IsNil)
+	c.Check(dest, Equals, obj)
+}
+
+// TestZeroAllocations checks the decoding paths and AppendText. Value,
+// MarshalText and MarshalJSON return new text, so they allocate.
+func (suite *FixedUUIDSuite) TestZeroAllocations(c *C) {
+	id := RandomUUID().Fixed()
+	var text interface{} = []byte(id.String())
+	jsonText := []byte(`"` + id.String() + `"`)
+	buf := make([]byte, 0, 36)
+	var u FixedUUID
+	var nu NullFixedUUID
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = u.Scan(text)
+		_ = u.UnmarshalText(text.([]byte))
+		_ = u.UnmarshalJSON(jsonText)
+		_ = nu.Scan(text)
+		buf = u.AppendText(buf[:0])
+	})
+	c.Check(allocs, Equals, 0.0)
+}
+
+func (suite *FixedUUIDSuite) TestFixedUUIDs(c *C) {
+	a, b := RandomUUID().Fixed(), RandomUUID().Fixed()
+	ls := FixedUUIDs{a, {}, b}
+	v, err := ls.Value()
+	c.Assert(err, IsNil)
+	c.Check(v, Equals, "{"+a.String()+","+nilUUID+","+b.String()+"}")
+	var dest FixedUUIDs
+	c.Assert(dest.Scan(v), IsNil)
+	c.Check(dest, DeepEquals, ls)
+	c.Assert(dest.Scan("{}"), IsNil)
+	c.Check(dest, HasLen, 0)
+	c.Check(dest.Scan("{abc}"), NotNil)
+	c.Check(dest.Scan("{"+a.String()+",NULL}"), NotNil)
+
+	var nulls NullFixedUUIDs
+	c.Assert(nulls.Scan("{"+a.String()+",NULL,"+nilUUID+"}"), IsNil)
+	c.Check(nulls, DeepEquals, NullFixedUUIDs{{a, true}, {}, {Valid: true}})
+	c.Check(mustValue(c, nulls), Equals, "{"+a.String()+",NULL,"+nilUUID+"}")
+
+	ids := ls.UUIDs()
+	c.Check(ids, DeepEquals, UUIDs{a.UUID(), FixedUUID{}.UUID(), b.UUID()})
+	c.Check(ids.Fixed(), DeepEquals, ls)
+}
+
+func (suite *FixedUUIDSuite) TestUUIDsSharedStorage(c *C) {
+	a, b := RandomUUID(), RandomUUID()
+	var ls UUIDs
+	c.Assert(ls.Scan("{"+a.String()+","+b.String()+"}"), IsNil)
+	c.Check(ls, DeepEquals, UUIDs{a, b})
+	c.Check(cap(ls[0]), Equals, 16)
+
+	// number of allocations doesn't depend on the number of elements
+	scanAllocs := func(n int) float64 {
+		src := []byte("{" + strings.Repeat(a.String()+",", n) + b.String() + "}")
+		return testing.AllocsPerRun(10, func() { _ = ls.Scan(src) })
+	}
+	c.Check(scanAllocs(1), Equals, scanAllocs(100))
+}
+
+type uuidSliceIterator struct {
+	ls UUIDs
+	i  int
+}
+
+func (it *uuidSliceIterator) Next() bool { it.i++; return it.i <= len(it.ls) }
+func (it *uuidSliceIterator) Get() UUID  { return it.ls[it.i-1] }
+
+func (suite *FixedUUIDSuite) TestExtractUUIDs(c *C) {
+	a, b := RandomUUID(), RandomUUID()
+	res := ExtractUUIDs(&uuidSliceIterator{ls: UUIDs{a, b, a.Fixed().UUID(), b}})
+	c.Check(res, DeepEquals, []UUID{a, b})
+}
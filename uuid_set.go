@@ -0,0 +1,189 @@
+package pgt
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"sort"
+
+	bat "github.com/robert-zaremba/go-bat"
+)
+
+// Compare returns -1, 0 or 1 if u is respectively less, equal or greater than o
+// using byte order, which is the same order as used by Postgresql for the `uuid`
+// type.
+func (u FixedUUID) Compare(o FixedUUID) int {
+	return bytes.Compare(u[:], o[:])
+}
+
+// SortUUIDs sorts UUIDs in Postgresql order. Empty UUIDs are placed first.
+func SortUUIDs(ls []UUID) {
+	sort.Slice(ls, func(i, j int) bool { return bytes.Compare(ls[i], ls[j]) < 0 })
+}
+
+// Sort sorts UUIDs in Postgresql order.
+func (ls FixedUUIDs) Sort() {
+	sort.Slice(ls, func(i, j int) bool { return ls[i].Compare(ls[j]) < 0 })
+}
+
+// SearchSorted searches for id in sorted ls. It returns the index where id is or
+// should be inserted and true if id was found.
+func (ls FixedUUIDs) SearchSorted(id FixedUUID) (int, bool) {
+	i := sort.Search(len(ls), func(i int) bool { return ls[i].Compare(id) >= 0 })
+	return i, i < len(ls) && ls[i] == id
+}
+
+// UUIDSet is a set of UUIDs. The set can be bound as a Postgresql `uuid[]` query
+// argument (eg: `WHERE id = ANY($1)`) and scanned from `uuid[]` column.
+// Empty UUIDs (NULLs) are never added to the set, the nil UUID is a regular
+// element.
+type UUIDSet map[FixedUUID]struct{}
+
+// NewUUIDSet creates a set from the given UUIDs
+func NewUUIDSet(ids ...UUID) UUIDSet {
+	s := make(UUIDSet, len(ids))
+	s.Add(ids...)
+	return s
+}
+
+// Add adds non empty ids to the set
+func (s UUIDSet) Add(ids ...UUID) {
+	for _, id := range ids {
+		if !id.Empty() {
+			s[id.Fixed()] = struct{}{}
+		}
+	}
+}
+
+// AddFixed adds ids to the set
+func (s UUIDSet) AddFixed(ids ...FixedUUID) {
+	for _, id := range ids {
+		s[id] = struct{}{}
+	}
+}
+
+// Remove removes ids from the set
+func (s UUIDSet) Remove(ids ...UUID) {
+	for _, id := range ids {
+		if !id.Empty() {
+			delete(s, id.Fixed())
+		}
+	}
+}
+
+// Has checks if id is in the set. It returns false for an empty id.
+func (s UUIDSet) Has(id UUID) bool {
+	if id.Empty() {
+		return false
+	}
+	_, ok := s[id.Fixed()]
+	return ok
+}
+
+// Len returns the number of elements in the set
+func (s UUIDSet) Len() int {
+	return len(s)
+}
+
+// Union returns a new set with elements from both s and o
+func (s UUIDSet) Union(o UUIDSet) UUIDSet {
+	res := make(UUIDSet, len(s)+len(o))
+	for id := range s {
+		res[id] = struct{}{}
+	}
+	for id := range o {
+		res[id] = struct{}{}
+	}
+	return res
+}
+
+// Intersect returns a new set with elements which are both in s and o
+func (s UUIDSet) Intersect(o UUIDSet) UUIDSet {
+	if len(o) < len(s) {
+		s, o = o, s
+	}
+	res := make(UUIDSet)
+	for id := range s {
+		if _, ok := o[id]; ok {
+			res[id] = struct{}{}
+		}
+	}
+	return res
+}
+
+// Difference returns a new set with elements of s which are not in o
+func (s UUIDSet) Difference(o UUIDSet) UUIDSet {
+	res := make(UUIDSet)
+	for id := range s {
+		if _, ok := o[id]; !ok {
+			res[id] = struct{}{}
+		}
+	}
+	return res
+}
+
+// Equals checks if both sets have the same elements
+func (s UUIDSet) Equals(o UUIDSet) bool {
+	if len(s) != len(o) {
+		return false
+	}
+	for id := range s {
+		if _, ok := o[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Sorted returns the set elements in Postgresql order
+func (s UUIDSet) Sorted() FixedUUIDs {
+	res := make(FixedUUIDs, 0, len(s))
+	for id := range s {
+		res = append(res, id)
+	}
+	res.Sort()
+	return res
+}
+
+// UUIDs returns the set elements as UUIDs in Postgresql order
+func (s UUIDSet) UUIDs() UUIDs {
+	sorted := s.Sorted()
+	res := make(UUIDs, len(sorted))
+	for i := range sorted {
+		res[i] = sorted[i].UUID()
+	}
+	return res
+}
+
+// Scan implements sql.Scanner interface for `uuid[]` source. NULL elements are
+// ignored.
+func (s *UUIDSet) Scan(src interface{}) error {
+	if src == nil {
+		*s = nil
+		return nil
+	}
+	bs, err := bat.UnsafeToBytes(src)
+	if err != nil {
+		return err
+	}
+	var ls NullFixedUUIDs
+	if err = ls.Scan(bs); err != nil {
+		return err
+	}
+	res := make(UUIDSet, len(ls))
+	for _, id := range ls {
+		if id.Valid {
+			res[id.UUID] = struct{}{}
+		}
+	}
+	*s = res
+	return nil
+}
+
+// Value implements sql/driver.Valuer interface. The elements are sorted to make
+// the value deterministic.
+func (s UUIDSet) Value() (driver.Value, error) {
+	if s == nil && NilArrayAsNull() {
+		return nil, nil
+	}
+	return s.Sorted().Value()
+}
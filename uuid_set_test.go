@@ -0,0 +1,74 @@
+package pgt
+
+import (
+	. "github.com/robert-zaremba/checkers"
+	. "gopkg.in/check.v1"
+)
+
+type UUIDSetSuite struct{}
+
+func (suite *UUIDSetSuite) TestSetOperations(c *C) {
+	a, b, x := RandomUUID(), RandomUUID(), RandomUUID()
+	s1 := NewUUIDSet(a, b, a, nil)
+	s2 := NewUUIDSet(b, x)
+	c.Check(s1.Len(), Equals, 2)
+	c.Check(s1.Has(a), IsTrue)
+	c.Check(s1.Has(x), IsFalse)
+
+	c.Check(s1.Union(s2).Equals(NewUUIDSet(a, b, x)), IsTrue)
+	c.Check(s1.Intersect(s2).Equals(NewUUIDSet(b)), IsTrue)
+	c.Check(s1.Difference(s2).Equals(NewUUIDSet(a)), IsTrue)
+	c.Check(s1.Equals(s2), IsFalse)
+
+	s1.Remove(a)
+	c.Check(s1.Equals(NewUUIDSet(b)), IsTrue)
+
+	// the nil UUID is an element, an empty UUID (NULL) is not
+	s1.AddFixed(FixedUUID{})
+	c.Check(s1.Len(), Equals, 2)
+	c.Check(s1.Has(FixedUUID{}.UUID()), IsTrue)
+	c.Check(s1.Has(nil), IsFalse)
+	s1.Remove(nil)
+	c.Check(s1.Len(), Equals, 2)
+}
+
+func (suite *UUIDSetSuite) TestSorted(c *C) {
+	ids := UUIDs{
+		MustParseUUID("ffffffff-0000-0000-0000-000000000000", nil),
+		MustParseUUID("00000000-0000-0000-0000-000000000002", nil),
+		MustParseUUID("10000000-0000-0000-0000-000000000000", nil),
+		MustParseUUID("00000000-0000-0000-0000-000000000001", nil),
+	}
+	s := NewUUIDSet(ids...)
+	sorted := s.UUIDs()
+	c.Check(sorted, DeepEquals, UUIDs{ids[3], ids[1], ids[2], ids[0]})
+
+	SortUUIDs(ids)
+	c.Check(ids, DeepEquals, sorted)
+
+	fixed := s.Sorted()
+	i, ok := fixed.SearchSorted(ids[2].Fixed())
+	c.Check(i, Equals, 2)
+	c.Check(ok, IsTrue)
+	_, ok = fixed.SearchSorted(RandomUUID().Fixed())
+	c.Check(ok, IsFalse)
+}
+
+func (suite *UUIDSetSuite) TestScanValue(c *C) {
+	a, b := RandomUUID(), RandomUUID()
+	s := NewUUIDSet(a, b)
+	v, err := s.Value()
+	c.Assert(err, IsNil)
+	expected, _ := s.Sorted().Value()
+	c.Check(v, Equals, expected)
+
+	var dest UUIDSet
+	c.Assert(dest.Scan("{"+a.String()+",NULL,"+b.String()+","+a.String()+"}"), IsNil)
+	c.Check(dest.Equals(s), IsTrue)
+	c.Assert(dest.Scan("{"+nilUUID+",NULL}"), IsNil)
+	c.Check(dest.Equals(UUIDSet{FixedUUID{}: {}}), IsTrue)
+
+	v, err = UUIDSet(nil).Value()
+	c.Assert(err, IsNil)
+	c.Check(v, Equals, "{}")
+}
@@ -1,6 +1,12 @@
 package pgt
 
 import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pborman/uuid"
 	. "github.com/robert-zaremba/checkers"
 	. "gopkg.in/check.v1"
 )
@@ -55,3 +61,169 @@ func (suite *UUIDSuite) TestUUIDMarshal(c *C) {
 	testMarshalJSON(obj, &destObj, c)
 	c.Assert(obj, DeepEquals, destObj)
 }
+
+func (suite *UUIDSuite) TestUUIDScan(c *C) {
+	id := RandomUUID()
+	var u UUID
+	c.Assert(u.Scan(id.String()), IsNil)
+	c.Check(u, SliceEquals, id)
+
+	c.Assert(u.Scan(nil), IsNil)
+	c.Check(u.Empty(), IsTrue)
+
+	c.Assert(u.Scan([]byte(id)), IsNil)
+	c.Check(u, SliceEquals, id)
+
+	for _, s := range []string{"", "xyz", "{" + id.String() + "}",
+		id.String()[:35] + "g", id.String()[:8] + "_" + id.String()[9:]} {
+		u = id
+		c.Check(u.Scan(s), ErrorMatches, "Invalid UUID.*", Comment(s))
+	}
+	c.Check(u.Scan(12), NotNil)
+}
+
+func (suite *UUIDSuite) TestParseUUIDStrict(c *C) {
+	id := RandomUUID()
+	u, err := ParseUUID(strings.ToUpper(id.String()))
+	c.Assert(err, IsNil)
+	c.Check(u, SliceEquals, id)
+
+	yamlString := func(s string) func(interface{}) error {
+		return func(v interface{}) error {
+			*(v.(**string)) = &s
+			return nil
+		}
+	}
+	for _, s := range []string{"urn:uuid:" + id.String(), "{" + id.String() + "}",
+		strings.ReplaceAll(id.String(), "-", "")} {
+		_, err = ParseUUID(s)
+		c.Check(err, ErrorMatches, ".*Invalid UUID.*", Comment(s))
+		c.Check(json.Unmarshal([]byte(`"`+s+`"`), &u), ErrorMatches, "Invalid UUID.*", Comment(s))
+		c.Check(u.UnmarshalYAML(yamlString(s)), ErrorMatches, ".*Invalid UUID.*", Comment(s))
+	}
+	c.Check(json.Unmarshal([]byte(`""`), &u), NotNil)
+}
+
+func (suite *UUIDSuite) TestParseUUIDArrayStrict(c *C) {
+	id := RandomUUID()
+	_, err := ParseUUIDArray([]byte("{" + id.String() + ",NULL}"))
+	c.Check(err, ErrorMatches, ".*NULL element at index 1")
+	_, err = ParseUUIDArray([]byte("{" + id.String() + ",abc}"))
+	c.Check(err, ErrorMatches, ".*element 1: Invalid UUID.*")
+}
+
+func (suite *UUIDSuite) TestNewUUIDv7(c *C) {
+	start := time.Now().Truncate(time.Millisecond)
+	prev := NewUUIDv7()
+	for i := 0; i < 10000; i++ {
+		u := NewUUIDv7()
+		c.Assert(bytes.Compare(prev, u), Equals, -1, Commentf("%s >= %s", prev, u))
+		prev = u
+	}
+	c.Check(prev.Version(), Equals, 7)
+	c.Check(prev[8]>>6, Equals, byte(2))
+	t, err := prev.Time()
+	c.Assert(err, IsNil)
+	c.Check(t.Time.Before(start), IsFalse)
+	c.Check(t.Time, WithinDuration, time.Now(), time.Second)
+}
+
+func (suite *UUIDSuite) TestNewUUIDv6(c *C) {
+	prev := NewUUIDv6()
+	for i := 0; i < 1000; i++ {
+		u := NewUUIDv6()
+		c.Assert(bytes.Compare(prev, u), Equals, -1)
+		prev = u
+	}
+	c.Check(prev.Version(), Equals, 6)
+	t, err := prev.Time()
+	c.Assert(err, IsNil)
+	c.Check(t.Time, WithinDuration, time.Now(), time.Second)
+
+	v1 := UUID(uuid.NewUUID())
+	t, err = v1.Time()
+	c.Assert(err, IsNil)
+	c.Check(t.Time, WithinDuration, time.Now(), time.Second)
+
+	_, err = RandomUUID().Time()
+	c.Check(err, NotNil)
+}
+
+func (suite *UUIDSuite) TestUUIDv7Range(c *C) {
+	now := time.Now()
+	u := NewUUIDv7()
+	min, max := UUIDv7Min(now.Add(-time.Millisecond)), UUIDv7Max(now.Add(time.Second))
+	c.Check(bytes.Compare(min, u), Equals, -1)
+	c.Check(bytes.Compare(u, max), Equals, -1)
+	c.Check(min.Version(), Equals, 7)
+	c.Check(max.Version(), Equals, 7)
+	c.Check(max.String()[19:], Equals, "bfff-ffffffffffff")
+
+	from, to := UUIDv7Range(now.Add(time.Second), now.Add(2*time.Second))
+	c.Check(bytes.Compare(u, from), Equals, -1)
+	t, err := to.Time()
+	c.Assert(err, IsNil)
+	c.Check(t.Time, Equals, now.Add(2*time.Second).Truncate(time.Millisecond).UTC())
+
+	// out of the Unix nanoseconds range
+	y3000 := time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, u := range []UUID{UUIDv7Min(y3000), UUIDv7Max(y3000)} {
+		t, err = u.Time()
+		c.Assert(err, IsNil)
+		c.Check(t.Time, Equals, y3000)
+	}
+	c.Check(UUIDv7Min(y3000).String()[:13], Equals, "1d8fda4c-e000")
+	c.Check(UUIDv7Max(time.Date(20000, 1, 1, 0, 0, 0, 0, time.UTC)).String()[:13], Equals, "ffffffff-ffff")
+	c.Check(UUIDv7Min(time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)).String()[:13], Equals, "00000000-0000")
+}
+
+func (suite *UUIDSuite) TestUUIDNull(c *C) {
+	var empty UUID
+	b, err := json.Marshal(empty)
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, "null")
+
+	dest := RandomUUID()
+	c.Assert(json.Unmarshal(b, &dest), IsNil)
+	c.Check(dest.Empty(), IsTrue)
+	c.Check(json.Unmarshal([]byte("12"), &dest), NotNil)
+
+	y, err := empty.MarshalYAML()
+	c.Assert(err, IsNil)
+	c.Check(y, IsNil)
+	id := RandomUUID()
+	y, err = id.MarshalYAML()
+	c.Assert(err, IsNil)
+	c.Check(y, Equals, id.String())
+
+	yamlNull := func(v interface{}) error { return nil }
+	dest = RandomUUID()
+	c.Assert(dest.UnmarshalYAML(yamlNull), IsNil)
+	c.Check(dest.Empty(), IsTrue)
+	yamlID := func(v interface{}) error {
+		s := id.String()
+		*(v.(**string)) = &s
+		return nil
+	}
+	c.Assert(dest.UnmarshalYAML(yamlID), IsNil)
+	c.Check(dest, SliceEquals, id)
+}
+
+func (suite *UUIDSuite) TestNullUUIDs(c *C) {
+	id := RandomUUID()
+	ls := NullUUIDs{id, nil}
+	v, err := ls.Value()
+	c.Assert(err, IsNil)
+	c.Check(v, Equals, "{"+id.String()+",NULL}")
+
+	var dest NullUUIDs
+	c.Assert(dest.Scan(v), IsNil)
+	c.Check(dest, DeepEquals, ls)
+
+	b, err := json.Marshal(ls)
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, `["`+id.String()+`",null]`)
+
+	_, err = UUIDs(ls).Value()
+	c.Check(err, ErrorMatches, ".*empty element at index 1")
+}
@@ -0,0 +1,183 @@
+package pgt
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// gregorianOffset is the number of 100ns intervals between the start of Gregorian
+// calendar (1582-10-15) and Unix epoch. It's used in UUID v1 and v6 timestamps.
+const gregorianOffset = 0x01B21DD213814000
+
+var v7gen struct {
+	sync.Mutex
+	lastMs  int64
+	counter uint16 // 12 bits
+}
+
+var v6gen struct {
+	sync.Mutex
+	last     uint64 // 60 bits
+	clockSeq uint16 // 14 bits
+	node     [6]byte
+}
+
+func fillRandom(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("Can't read random bytes: %v", err))
+	}
+}
+
+// NewUUIDv7 returns a new, time ordered UUID version 7 (RFC 9562): 48 bits of
+// Unix timestamp in milliseconds followed by random bits.
+// UUIDs generated by this function in one process are strictly increasing (in
+// byte order, which is also the Postgresql `uuid` order): 12 bits after the
+// timestamp are used as a counter, randomly seeded every millisecond. When the
+// counter overflows or the clock goes backwards the timestamp of the previous
+// UUID is reused or incremented.
+func NewUUIDv7() UUID {
+	u := make(UUID, 16)
+	fillRandom(u[8:])
+
+	v7gen.Lock()
+	ms := time.Now().UnixMilli()
+	if ms > v7gen.lastMs {
+		var seed [2]byte
+		fillRandom(seed[:])
+		v7gen.lastMs = ms
+		v7gen.counter = binary.BigEndian.Uint16(seed[:]) & 0x7ff // leave half of the space for increments
+	} else {
+		v7gen.counter++
+		if v7gen.counter > 0xfff {
+			v7gen.lastMs++
+			v7gen.counter = 0
+		}
+	}
+	ms, counter := v7gen.lastMs, v7gen.counter
+	v7gen.Unlock()
+
+	putUUIDv7Time(u, ms)
+	u[6] = 0x70 | byte(counter>>8)
+	u[7] = byte(counter)
+	u[8] = 0x80 | u[8]&0x3f
+	return u
+}
+
+// putUUIDv7Time puts the 48 bits Unix timestamp in milliseconds into u. Out of
+// range timestamps are clamped.
+func putUUIDv7Time(u UUID, ms int64) {
+	switch {
+	case ms < 0:
+		ms = 0
+	case ms >= 1<<48:
+		ms = 1<<48 - 1
+	}
+	u[0] = byte(ms >> 40)
+	u[1] = byte(ms >> 32)
+	binary.BigEndian.PutUint32(u[2:], uint32(ms))
+}
+
+// NewUUIDv6 returns a new, time ordered UUID version 6 (RFC 9562): a
+// field-compatible version of UUID v1 with the 60 bits Gregorian timestamp
+// (100ns precision) stored from the most significant bits. The clock sequence and
+// the node are random per process. UUIDs generated by this function in one
+// process are strictly increasing.
+func NewUUIDv6() UUID {
+	now := uint64(time.Now().UnixNano()/100) + gregorianOffset
+
+	v6gen.Lock()
+	if v6gen.last == 0 {
+		var seed [8]byte
+		fillRandom(seed[:])
+		v6gen.clockSeq = binary.BigEndian.Uint16(seed[:]) & 0x3fff
+		copy(v6gen.node[:], seed[2:])
+		v6gen.node[0] |= 0x01 // multicast bit marks a random node
+	}
+	if now <= v6gen.last {
+		now = v6gen.last + 1
+	}
+	v6gen.last = now
+	clockSeq, node := v6gen.clockSeq, v6gen.node
+	v6gen.Unlock()
+
+	u := make(UUID, 16)
+	binary.BigEndian.PutUint32(u[0:], uint32(now>>28))
+	binary.BigEndian.PutUint16(u[4:], uint16(now>>12))
+	binary.BigEndian.PutUint16(u[6:], 0x6000|uint16(now&0xfff))
+	binary.BigEndian.PutUint16(u[8:], 0x8000|clockSeq)
+	copy(u[10:], node[:])
+	return u
+}
+
+// Version returns the UUID version number or 0 for an empty UUID.
+func (u UUID) Version() int {
+	if len(u) != 16 {
+		return 0
+	}
+	return int(u[6] >> 4)
+}
+
+// Time returns the timestamp embedded in the UUID version 1, 6 or 7.
+func (u UUID) Time() (Time, error) {
+	var t time.Time
+	switch u.Version() {
+	case 1:
+		ts := uint64(binary.BigEndian.Uint32(u[0:])) |
+			uint64(binary.BigEndian.Uint16(u[4:]))<<32 |
+			uint64(binary.BigEndian.Uint16(u[6:])&0xfff)<<48
+		t = gregorianToTime(ts)
+	case 6:
+		ts := uint64(binary.BigEndian.Uint32(u[0:]))<<28 |
+			uint64(binary.BigEndian.Uint16(u[4:]))<<12 |
+			uint64(binary.BigEndian.Uint16(u[6:])&0xfff)
+		t = gregorianToTime(ts)
+	case 7:
+		ms := int64(u[0])<<40 | int64(u[1])<<32 | int64(binary.BigEndian.Uint32(u[2:]))
+		t = time.Unix(ms/1e3, ms%1e3*int64(time.Millisecond))
+	default:
+		return Time{}, fmt.Errorf("UUID version %d doesn't contain a timestamp", u.Version())
+	}
+	return NewTime(t), nil
+}
+
+func gregorianToTime(ts uint64) time.Time {
+	unix100ns := int64(ts) - gregorianOffset
+	return time.Unix(unix100ns/1e7, unix100ns%1e7*100)
+}
+
+// UUIDv7Min returns the smallest UUID version 7 with the timestamp of t
+// (truncated to milliseconds). Together with UUIDv7Max it can be used to select
+// rows by UUID v7 primary key in a time range using the index:
+//
+//	WHERE id >= $1 AND id <= $2  -- UUIDv7Min(from), UUIDv7Max(to)
+func UUIDv7Min(t time.Time) UUID {
+	u := make(UUID, 16)
+	putUUIDv7Time(u, t.UnixMilli())
+	u[6] = 0x70
+	u[8] = 0x80
+	return u
+}
+
+// UUIDv7Max returns the largest UUID version 7 with the timestamp of t
+// (truncated to milliseconds). See UUIDv7Min.
+func UUIDv7Max(t time.Time) UUID {
+	u := make(UUID, 16)
+	putUUIDv7Time(u, t.UnixMilli())
+	for i := 6; i < 16; i++ {
+		u[i] = 0xff
+	}
+	u[6] = 0x7f
+	u[8] = 0xbf
+	return u
+}
+
+// UUIDv7Range returns the boundaries of UUIDs version 7 generated in the
+// [from, to) time range, with millisecond precision:
+//
+//	WHERE id >= $1 AND id < $2
+func UUIDv7Range(from, to time.Time) (UUID, UUID) {
+	return UUIDv7Min(from), UUIDv7Min(to)
+}